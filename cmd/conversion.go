@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/config"
+	"github.com/pluralsh/cluster-api-migration/pkg/overrides"
+	"github.com/pluralsh/cluster-api-migration/pkg/rules"
+)
+
+// conversion holds the steps applied to values after they were read, so that convert, diff, verify and from-capi
+// produce values that can be compared with each other.
+type conversion struct {
+	rules        *rules.Rules
+	overrides    []string
+	chartWorkers api.DefaultWorkers
+}
+
+// newConversion reads the rules file and default pools of the chart selected in validated output settings.
+func newConversion(output config.Output) (*conversion, error) {
+	workerRules, err := readRules(output.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	chartWorkers, err := defaultWorkers(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversion{rules: workerRules, overrides: output.Overrides, chartWorkers: chartWorkers}, nil
+}
+
+// convert converts the cluster with the migrator, then applies rules, overrides and default pools of the chart.
+func (c *conversion) convert(ctx context.Context, m api.Migrator) (*api.Values, *api.ConversionReport, error) {
+	values, report, err := m.Convert(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = c.rules.Apply(values, report); err != nil {
+		return nil, nil, err
+	}
+
+	if values, err = overrides.Apply(values, report, c.overrides...); err != nil {
+		return nil, nil, err
+	}
+	values.SetDefaultWorkers(c.chartWorkers)
+
+	return values, report, nil
+}
+
+// rebuilt applies default pools of the chart to values rebuilt from Cluster API objects. Rules and overrides
+// are not applied, the objects were rendered from values they were applied to already.
+func (c *conversion) rebuilt(values *api.Values) {
+	values.SetDefaultWorkers(c.chartWorkers)
+}
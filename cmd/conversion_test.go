@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fixture = "../pkg/migrator/testdata/aws.fixture.json"
+
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	root := newRootCommand()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(args)
+
+	err := root.Execute()
+	return out.String(), err
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// TestDiffAppliesOverridesAsConvert checks that values written by convert with overrides do not differ
+// from the cluster converted by diff with the same overrides.
+func TestDiffAppliesOverridesAsConvert(t *testing.T) {
+	overrides := writeFile(t, "overrides.yaml", `
+workers:
+  aws:
+    small-burst-on-demand:
+      spec:
+        instanceType: t3.xlarge
+`)
+	values := filepath.Join(t.TempDir(), "values.yaml")
+	report := filepath.Join(t.TempDir(), "report.yaml")
+
+	if _, err := run(t, "convert", "--replay", fixture, "--overrides", overrides, "--output-file", values, "--report-file", report); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run(t, "diff", "--from", values, "--replay", fixture, "--overrides", overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "No changes." {
+		t.Errorf("diff with the overrides of convert reported changes:\n%s", out)
+	}
+
+	out, err = run(t, "diff", "--from", values, "--replay", fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "workers.aws.small-burst-on-demand.spec.instanceType") {
+		t.Errorf("diff without the overrides did not report the overridden instance type:\n%s", out)
+	}
+}
+
+// TestDiffAppliesRulesAsConvert checks that pools renamed by rules of convert are matched by diff with the same rules.
+func TestDiffAppliesRulesAsConvert(t *testing.T) {
+	rules := writeFile(t, "rules.yaml", `
+rules:
+- name: naming
+  selector:
+    name: "*-burst-*"
+  rename: pool-{name}
+`)
+	values := filepath.Join(t.TempDir(), "values.yaml")
+	report := filepath.Join(t.TempDir(), "report.yaml")
+
+	if _, err := run(t, "convert", "--replay", fixture, "--rules", rules, "--output-file", values, "--report-file", report); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run(t, "diff", "--from", values, "--replay", fixture, "--rules", rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "No changes." {
+		t.Errorf("diff with the rules of convert reported changes:\n%s", out)
+	}
+
+	out, err = run(t, "diff", "--from", values, "--replay", fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "workers.aws.pool-small-burst-on-demand") {
+		t.Errorf("diff without the rules did not report the renamed pool:\n%s", out)
+	}
+}
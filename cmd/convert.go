@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/config"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+	"github.com/pluralsh/cluster-api-migration/pkg/migrator"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+	"github.com/pluralsh/cluster-api-migration/pkg/rules"
+)
+
+func newConvertCommand(options *options) *cobra.Command {
+	var mode, format, path, reportPath, chartSchema, chartValues, chartProfile, rulesPath, recordPath, replayPath string
+	var split bool
+	var overrideFiles []string
+
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Read the existing cluster and print values for the cluster-api-cluster chart",
+		Long: "Read the existing cluster and print values for the cluster-api-cluster chart.\n\n" +
+			"With --mode manifests Cluster API objects are printed instead, which adopt the cluster with kubectl apply.\n" +
+			"With --mode topology a ClusterClass is derived from the cluster and the Cluster uses it through its topology.\n" +
+			"Only azure supports topology, CAPA and CAPG do not provide templates of their managed objects.\n\n" +
+			"Raw responses of the cloud APIs can be saved with --record and converted again later with --replay,\n" +
+			"which needs neither credentials nor network access.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(recordPath) > 0 && len(replayPath) > 0 {
+				return fmt.Errorf("--record and --replay cannot be used together")
+			}
+
+			output, err := options.output()
+			if err != nil {
+				return err
+			}
+
+			override(&output.Mode, mode)
+			override(&output.Format, format)
+			override(&output.Path, path)
+			override(&output.ReportPath, reportPath)
+			override(&output.ChartSchema, chartSchema)
+			override(&output.ChartValues, chartValues)
+			override(&output.ChartProfile, chartProfile)
+			override(&output.Rules, rulesPath)
+			output.Split = output.Split || split
+			output.Overrides = append(output.Overrides, overrideFiles...)
+			if err = output.Validate(); err != nil {
+				return err
+			}
+
+			pipeline, err := newConversion(output)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := options.context(cmd.Context())
+			defer cancel()
+
+			var m api.Migrator
+			if len(replayPath) > 0 {
+				m, err = newReplayMigrator(replayPath)
+			} else {
+				var c *config.Config
+				if c, err = options.config(); err != nil {
+					return err
+				}
+				m, err = newMigrator(ctx, c)
+			}
+			if err != nil {
+				return err
+			}
+
+			values, report, err := pipeline.convert(ctx, m)
+			if err != nil {
+				return err
+			}
+
+			if len(recordPath) > 0 {
+				fixture, err := migrator.Record(m)
+				if err != nil {
+					return err
+				}
+				if err = migrator.WriteFixture(recordPath, fixture); err != nil {
+					return err
+				}
+			}
+
+			if err = validateValues(values, output.ChartSchema); err != nil {
+				return err
+			}
+
+			switch output.Mode {
+			case config.OutputModeManifests:
+				err = writeManifests(cmd.OutOrStdout(), output, manifests.Render, values, report)
+			case config.OutputModeTopology:
+				err = writeManifests(cmd.OutOrStdout(), output, manifests.RenderTopology, values, report)
+			default:
+				err = writeValues(cmd.OutOrStdout(), output, values)
+			}
+			if err != nil {
+				return err
+			}
+
+			return writeReport(cmd, output, report)
+		},
+	}
+
+	cmd.Flags().StringVar(&mode, "mode", "", "what to generate, one of: values, manifests, topology (azure only) (default values)")
+	cmd.Flags().StringVarP(&format, "output", "o", "", "output format, one of: yaml, json, set (default yaml)")
+	cmd.Flags().StringVar(&path, "output-file", "", "file to write values to instead of standard output")
+	cmd.Flags().BoolVar(&split, "split", false, "write cluster and workers values as separate documents, or files if --output-file is set")
+	cmd.Flags().StringVar(&reportPath, "report-file", "", "file to write conversion report to instead of standard error")
+	cmd.Flags().StringVar(&chartValues, "chart-values", "", "values.yaml of the cluster-api-cluster chart, or the chart directory, to read default worker pools from")
+	cmd.Flags().StringVar(&chartProfile, "chart-profile", "", fmt.Sprintf("built-in default worker pools of the chart, one of: %s (default %s)", strings.Join(api.ChartProfiles(), ", "), api.LatestChartProfile))
+	cmd.Flags().StringVar(&rulesPath, "rules", "", "rules file that renames, drops or changes worker pools of the converted values")
+	cmd.Flags().StringSliceVar(&overrideFiles, "overrides", nil, "partial values files merged into the converted values in the given order, can be repeated")
+	cmd.Flags().StringVar(&chartSchema, "chart-schema", "", "values.schema.json of the cluster-api-cluster chart, or the chart directory, to validate values against")
+	cmd.Flags().StringVar(&recordPath, "record", "", "file to save raw responses of the cloud APIs to")
+	cmd.Flags().StringVar(&replayPath, "replay", "", "file with responses saved by --record to convert instead of calling the cloud APIs")
+
+	return cmd
+}
+
+// readRules reads the rules file if its path is set. Nil rules change nothing.
+func readRules(path string) (*rules.Rules, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	return rules.Read(path)
+}
+
+// defaultWorkers returns worker pools the chart creates by default, read from the chart values if their path is set,
+// or from the built-in chart profile.
+func defaultWorkers(output config.Output) (api.DefaultWorkers, error) {
+	if len(output.ChartValues) > 0 {
+		return migrator.ReadDefaultWorkers(output.ChartValues)
+	}
+
+	if len(output.ChartProfile) > 0 {
+		return api.ChartProfile(output.ChartProfile)
+	}
+
+	return api.LatestDefaultWorkers(), nil
+}
+
+// writeOutput prints the object in the output format to the output file or to w.
+func writeOutput(w io.Writer, output config.Output, i interface{}) error {
+	printer, err := resources.NewPrinter(output.Format)
+	if err != nil {
+		return err
+	}
+
+	if len(output.Path) == 0 {
+		return printer.Print(w, i)
+	}
+
+	return printer.PrintFile(output.Path, i)
+}
+
+// writeValues prints the values, split into cluster and workers documents if requested.
+// Split values are written to two files named after the output file.
+func writeValues(w io.Writer, output config.Output, values *api.Values) error {
+	if !output.Split {
+		return writeOutput(w, output, values)
+	}
+
+	printer, err := resources.NewPrinter(output.Format)
+	if err != nil {
+		return err
+	}
+
+	cluster, workers := values.Split()
+	if len(output.Path) == 0 {
+		return printer.Print(w, cluster, workers)
+	}
+
+	if err = printer.PrintFile(splitPath(output.Path, "cluster"), cluster); err != nil {
+		return err
+	}
+
+	return printer.PrintFile(splitPath(output.Path, "workers"), workers)
+}
+
+// splitPath adds suffix to the file name before its extension, i.e. values.yaml becomes values-cluster.yaml.
+func splitPath(path, suffix string) string {
+	extension := filepath.Ext(path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, extension), suffix, extension)
+}
+
+// writeManifests renders Cluster API objects from the values. Values that cannot be expressed
+// in the objects are added to the report.
+func writeManifests(w io.Writer, output config.Output, render manifests.Renderer, values *api.Values, report *api.ConversionReport) error {
+	objects, err := render(values, report)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if output.Format == config.OutputFormatJSON {
+		data, err = manifests.JSON(objects)
+	} else {
+		data, err = manifests.YAML(objects)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(output.Path) == 0 {
+		_, err = w.Write(data)
+		return err
+	}
+
+	return os.WriteFile(output.Path, data, 0644)
+}
+
+// writeReport prints values that have to be checked by hand before applying the chart.
+// If report path is set, the whole report is written there in the output format.
+func writeReport(cmd *cobra.Command, output config.Output, report *api.ConversionReport) error {
+	if len(output.ReportPath) > 0 {
+		if report == nil {
+			report = api.NewConversionReport()
+		}
+
+		format := output.Format
+		if format == config.OutputFormatSet {
+			format = config.OutputFormatYAML
+		}
+
+		return writeOutput(cmd.OutOrStdout(), config.Output{Format: format, Path: output.ReportPath}, report)
+	}
+
+	if report.Empty() {
+		return nil
+	}
+
+	fmt.Fprintln(cmd.ErrOrStderr(), "Following values have to be checked before applying the chart:")
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", warning)
+	}
+
+	return nil
+}
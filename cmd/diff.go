@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/config"
+	"github.com/pluralsh/cluster-api-migration/pkg/diff"
+	"github.com/pluralsh/cluster-api-migration/pkg/migrator"
+)
+
+func newDiffCommand(options *options) *cobra.Command {
+	var fromPath, toPath, replayPath, format, chartValues, chartProfile, rulesPath string
+	var overrideFiles []string
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show changes between previous values and a fresh conversion",
+		Long: "Show changes between previous values and a fresh conversion.\n\n" +
+			"Values from --from, i.e. the values.yaml committed earlier, are compared with the cluster converted\n" +
+			"from the cloud, with responses saved by convert --record, or with another values file given by --to.\n" +
+			"Workers are matched by pool name and list items, i.e. subnets, by their ID or name. Default pools of the chart\n" +
+			"are set to null in the converted values as convert does, select them with --chart-values or --chart-profile.\n" +
+			"Rules and overrides of the configuration file, --rules and --overrides are applied as convert does,\n" +
+			"so that values written by convert with them do not differ.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(fromPath) == 0 {
+				return fmt.Errorf("--from cannot be empty, ensure that it is set")
+			}
+			if len(toPath) > 0 && len(replayPath) > 0 {
+				return fmt.Errorf("--to and --replay cannot be used together")
+			}
+
+			output, err := options.output()
+			if err != nil {
+				return err
+			}
+
+			override(&output.Format, format)
+			override(&output.ChartValues, chartValues)
+			override(&output.ChartProfile, chartProfile)
+			override(&output.Rules, rulesPath)
+			output.Overrides = append(output.Overrides, overrideFiles...)
+			if err = output.Validate(); err != nil {
+				return err
+			}
+			if output.Format == config.OutputFormatSet {
+				return &api.InvalidConfigError{Err: fmt.Errorf("changes can be printed only as %s or %s", config.OutputFormatYAML, config.OutputFormatJSON)}
+			}
+
+			from, err := migrator.ReadValues(fromPath)
+			if err != nil {
+				return err
+			}
+
+			to, err := diffTarget(cmd, options, output, toPath, replayPath)
+			if err != nil {
+				return err
+			}
+
+			changes, err := diff.Values(from, to)
+			if err != nil {
+				return err
+			}
+
+			if len(output.Format) > 0 {
+				return writeOutput(cmd.OutOrStdout(), output, changes)
+			}
+
+			if len(changes) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No changes.")
+			}
+			for _, change := range changes {
+				fmt.Fprintln(cmd.OutOrStdout(), change)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromPath, "from", "", "values file to compare, i.e. values.yaml committed earlier")
+	cmd.Flags().StringVar(&toPath, "to", "", "values file to compare with instead of converting the cluster")
+	cmd.Flags().StringVar(&replayPath, "replay", "", "file with responses saved by convert --record to convert instead of calling the cloud APIs")
+	cmd.Flags().StringVarP(&format, "output", "o", "", "print changes in the format, one of: yaml, json (default list of changes)")
+	cmd.Flags().StringVar(&chartValues, "chart-values", "", "values.yaml of the cluster-api-cluster chart, or the chart directory, to read default worker pools from")
+	cmd.Flags().StringVar(&chartProfile, "chart-profile", "", fmt.Sprintf("built-in default worker pools of the chart, one of: %s (default %s)", strings.Join(api.ChartProfiles(), ", "), api.LatestChartProfile))
+	cmd.Flags().StringVar(&rulesPath, "rules", "", "rules file that renames, drops or changes worker pools of the converted values")
+	cmd.Flags().StringSliceVar(&overrideFiles, "overrides", nil, "partial values files merged into the converted values in the given order, can be repeated")
+
+	return cmd
+}
+
+// diffTarget returns values the previous ones are compared with.
+func diffTarget(cmd *cobra.Command, options *options, output config.Output, toPath, replayPath string) (*api.Values, error) {
+	if len(toPath) > 0 {
+		return migrator.ReadValues(toPath)
+	}
+
+	pipeline, err := newConversion(output)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := options.context(cmd.Context())
+	defer cancel()
+
+	var m api.Migrator
+	if len(replayPath) > 0 {
+		m, err = newReplayMigrator(replayPath)
+	} else {
+		var c *config.Config
+		if c, err = options.config(); err != nil {
+			return nil, err
+		}
+		m, err = newMigrator(ctx, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values, _, err := pipeline.convert(ctx, m)
+	return values, err
+}
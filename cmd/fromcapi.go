@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+)
+
+func newFromCAPICommand(options *options) *cobra.Command {
+	var managementKubeconfig, namespace, clusterName, format, path, reportPath, chartSchema, chartValues, chartProfile string
+	var split bool
+
+	cmd := &cobra.Command{
+		Use:   "from-capi",
+		Short: "Rebuild values for the cluster-api-cluster chart from Cluster API objects in a management cluster",
+		Long: "Rebuild values for the cluster-api-cluster chart from Cluster API objects in a management cluster.\n\n" +
+			"The Cluster selected with --cluster is read together with its managed control plane, managed cluster\n" +
+			"and machine pools, using --management-kubeconfig. Settings of the objects that cannot be expressed\n" +
+			"in the values are listed in the report. Rules and overrides are not applied, as the objects were\n" +
+			"rendered from values they were applied to already.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(clusterName) == 0 {
+				return &api.InvalidConfigError{Err: fmt.Errorf("--cluster cannot be empty, ensure that it is set")}
+			}
+
+			output, err := options.output()
+			if err != nil {
+				return err
+			}
+
+			override(&output.Format, format)
+			override(&output.Path, path)
+			override(&output.ReportPath, reportPath)
+			override(&output.ChartSchema, chartSchema)
+			override(&output.ChartValues, chartValues)
+			override(&output.ChartProfile, chartProfile)
+			output.Split = output.Split || split
+			if err = output.Validate(); err != nil {
+				return err
+			}
+
+			pipeline, err := newConversion(output)
+			if err != nil {
+				return err
+			}
+
+			restConfig, namespace, err := managementCluster(managementKubeconfig, namespace)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := options.context(cmd.Context())
+			defer cancel()
+
+			objects, err := manifests.Read(ctx, restConfig, namespace, clusterName)
+			if err != nil {
+				return err
+			}
+
+			report := api.NewConversionReport()
+			values, err := manifests.Values(objects, report)
+			if err != nil {
+				return err
+			}
+
+			pipeline.rebuilt(values)
+
+			if err = validateValues(values, output.ChartSchema); err != nil {
+				return err
+			}
+
+			if err = writeValues(cmd.OutOrStdout(), output, values); err != nil {
+				return err
+			}
+
+			return writeReport(cmd, output, report)
+		},
+	}
+
+	cmd.Flags().StringVar(&managementKubeconfig, "management-kubeconfig", "", "path to the kubeconfig of the management cluster, defaults to KUBECONFIG")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "namespace of the Cluster (default namespace of the kubeconfig context)")
+	cmd.Flags().StringVar(&clusterName, "cluster", "", "name of the Cluster to read")
+	cmd.Flags().StringVarP(&format, "output", "o", "", "output format, one of: yaml, json, set (default yaml)")
+	cmd.Flags().StringVar(&path, "output-file", "", "file to write values to instead of standard output")
+	cmd.Flags().BoolVar(&split, "split", false, "write cluster and workers values as separate documents, or files if --output-file is set")
+	cmd.Flags().StringVar(&reportPath, "report-file", "", "file to write the report to instead of standard error")
+	cmd.Flags().StringVar(&chartValues, "chart-values", "", "values.yaml of the cluster-api-cluster chart, or the chart directory, to read default worker pools from")
+	cmd.Flags().StringVar(&chartProfile, "chart-profile", "", fmt.Sprintf("built-in default worker pools of the chart, one of: %s (default %s)", strings.Join(api.ChartProfiles(), ", "), api.LatestChartProfile))
+	cmd.Flags().StringVar(&chartSchema, "chart-schema", "", "values.schema.json of the cluster-api-cluster chart, or the chart directory, to validate values against")
+
+	return cmd
+}
+
+// managementCluster loads client configuration of the management cluster. The namespace of the kubeconfig context
+// is returned if namespace is empty.
+func managementCluster(kubeconfig, namespace string) (*rest.Config, string, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{},
+	)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(namespace) == 0 {
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return restConfig, namespace, nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/config"
+	"github.com/pluralsh/cluster-api-migration/pkg/migrator"
+)
+
+// options holds the flags shared by all commands that talk to a cluster.
+// Flags that are set take precedence over values from the configuration file.
+type options struct {
+	configPath     string
+	provider       string
+	name           string
+	region         string
+	project        string
+	subscriptionID string
+	resourceGroup  string
+	kubeconfig     string
+	timeout        time.Duration
+}
+
+func (o *options) addFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.configPath, "config", "c", "", "path to the migration configuration file (YAML or JSON)")
+	flags.StringVarP(&o.provider, "provider", "p", "", fmt.Sprintf("cluster provider, one of: %s", providerNames()))
+	flags.StringVarP(&o.name, "name", "n", "", "name of the cluster to migrate")
+	flags.StringVar(&o.region, "region", "", "region the cluster lives in (aws, gcp), defaults to AWS_REGION for aws")
+	flags.StringVar(&o.project, "project", "", "GCP project the cluster lives in (gcp)")
+	flags.StringVar(&o.subscriptionID, "subscription-id", "", "Azure subscription ID (azure), defaults to AZURE_SUBSCRIPTION_ID")
+	flags.StringVar(&o.resourceGroup, "resource-group", "", "Azure resource group the cluster lives in (azure)")
+	flags.StringVar(&o.kubeconfig, "kubeconfig", "", "path to the kubeconfig of the cluster (gcp), defaults to KUBECONFIG")
+	flags.DurationVar(&o.timeout, "timeout", 0, "maximum duration of the whole command, i.e. 10m, no limit if not set")
+}
+
+// config loads the configuration file if it was provided, applies flags on top of it,
+// falls back to the environment variables used by the cloud SDKs and validates the result.
+// Flags apply to configurations of the built-in providers, other providers can only be configured with the file.
+func (o *options) config() (*config.Config, error) {
+	c := &config.Config{}
+	if len(o.configPath) > 0 {
+		var err error
+		if c, err = config.Read(o.configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	override((*string)(&c.Provider), o.provider)
+
+	// Sections of unsupported providers are reported by Validate.
+	if section, err := c.Section(c.Provider); err == nil {
+		switch section := section.(type) {
+		case *api.AWSConfiguration:
+			override(&section.ClusterName, o.name)
+			override(&section.Region, o.region)
+			fallback(&section.Region, os.Getenv("AWS_REGION"))
+		case *api.AzureConfiguration:
+			override(&section.Name, o.name)
+			override(&section.ResourceGroup, o.resourceGroup)
+			override(&section.SubscriptionID, o.subscriptionID)
+			fallback(&section.SubscriptionID, os.Getenv("AZURE_SUBSCRIPTION_ID"))
+		case *api.GCPConfiguration:
+			override(&section.Name, o.name)
+			override(&section.Project, o.project)
+			override(&section.Region, o.region)
+			override(&section.KubeconfigPath, o.kubeconfig)
+			fallback(&section.KubeconfigPath, os.Getenv("KUBECONFIG"))
+		case *api.KindConfiguration:
+			override(&section.ClusterName, o.name)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// output reads output settings from the configuration file, if it was provided, without validating the rest of it.
+func (o *options) output() (config.Output, error) {
+	if len(o.configPath) == 0 {
+		return config.Output{}, nil
+	}
+
+	c, err := config.Read(o.configPath)
+	if err != nil {
+		return config.Output{}, err
+	}
+
+	return c.Output, nil
+}
+
+// context applies --timeout to the command context.
+func (o *options) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout > 0 {
+		return context.WithTimeout(parent, o.timeout)
+	}
+
+	return context.WithCancel(parent)
+}
+
+// newMigrator creates migrator for the provider selected in validated configuration.
+func newMigrator(ctx context.Context, c *config.Config) (api.Migrator, error) {
+	return migrator.NewMigrator(ctx, c.Provider, c.Configuration())
+}
+
+// providerNames returns comma separated names of all registered providers.
+func providerNames() string {
+	names := make([]string, 0)
+	for _, provider := range migrator.Providers() {
+		names = append(names, string(provider))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// newReplayMigrator creates migrator that converts responses saved by convert --record.
+func newReplayMigrator(path string) (api.Migrator, error) {
+	fixture, err := migrator.ReadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrator.NewReplayMigrator(fixture)
+}
+
+// override sets target to value unless value is empty.
+func override(target *string, value string) {
+	if len(value) > 0 {
+		*target = value
+	}
+}
+
+// fallback sets target to value only if target is empty.
+func fallback(target *string, value string) {
+	if len(*target) == 0 {
+		*target = value
+	}
+}
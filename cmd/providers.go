@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/migrator"
+)
+
+func newProvidersCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "providers",
+		Short: "List cluster providers that can be migrated",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, provider := range migrator.Providers() {
+				fmt.Fprintln(cmd.OutOrStdout(), provider)
+			}
+
+			return nil
+		},
+	}
+}
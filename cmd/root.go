@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix is prepended to the upper-cased flag name to build the environment variable
+// that can be used instead of a flag, i.e. --resource-group can be set with CAPI_MIGRATION_RESOURCE_GROUP.
+const envPrefix = "CAPI_MIGRATION"
+
+func newRootCommand() *cobra.Command {
+	options := &options{}
+
+	root := &cobra.Command{
+		Use:           "cluster-api-migration",
+		Short:         "Migrate existing Kubernetes clusters to Cluster API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return bindEnv(cmd.Flags())
+		},
+	}
+
+	options.addFlags(root.PersistentFlags())
+
+	root.AddCommand(
+		newConvertCommand(options),
+		newFromCAPICommand(options),
+		newVerifyCommand(options),
+		newDiffCommand(options),
+		newTagCommand(options),
+		newUntagCommand(options),
+		newValidateCommand(options),
+		newUpgradeCommand(),
+		newProvidersCommand(),
+		newVersionCommand(),
+	)
+
+	return root
+}
+
+// bindEnv sets every flag that was not passed explicitly from its environment variable, if present.
+func bindEnv(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+
+		if value, ok := os.LookupEnv(envName(flag.Name)); ok {
+			if setErr := flags.Set(flag.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value of %s: %w", envName(flag.Name), setErr)
+			}
+		}
+	})
+
+	return err
+}
+
+func envName(flag string) string {
+	return fmt.Sprintf("%s_%s", envPrefix, strings.ToUpper(strings.ReplaceAll(flag, "-", "_")))
+}
+
+// Execute runs the root command and exits with non-zero code on failure.
+// Interrupting the process cancels calls to the cloud provider that are in progress.
+func Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		stop()
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
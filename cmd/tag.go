@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/journal"
+)
+
+const defaultJournalPath = "tag-journal.json"
+
+func newTagCommand(options *options) *cobra.Command {
+	var tags map[string]string
+	var journalPath string
+	var dryRun, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Add tags required by Cluster API to the existing cluster resources",
+		Long: "Add tags required by Cluster API to the existing cluster resources.\n\n" +
+			"Tags are compared with the ones already set on the resources first and the plan is printed.\n" +
+			"Nothing is written until the plan is approved, or --yes is passed.\n\n" +
+			"Progress is recorded in --journal after every resource together with the previous tag values.\n" +
+			"If the run fails, running it again resumes from the journal and the untag command reverts it.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := options.config()
+			if err != nil {
+				return err
+			}
+
+			if c.Tags == nil {
+				c.Tags = map[string]string{}
+			}
+			for key, value := range tags {
+				c.Tags[key] = value
+			}
+			if len(c.Tags) == 0 {
+				return fmt.Errorf("tags cannot be empty, ensure that --tags or tags in configuration file are set")
+			}
+
+			ctx, cancel := options.context(cmd.Context())
+			defer cancel()
+
+			m, err := newMigrator(ctx, c)
+			if err != nil {
+				return err
+			}
+
+			j := journal.New(journalPath)
+			plan, err := j.Load()
+			if err != nil {
+				return err
+			}
+
+			if plan != nil {
+				if err = checkJournal(j, plan, c.Provider, c.Tags); err != nil {
+					return err
+				}
+				if !plan.HasChanges() {
+					fmt.Fprintf(cmd.OutOrStdout(), "All tags from %s are applied already.\n", j.Path())
+					return nil
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Resuming tagging run from %s.\n", j.Path())
+			} else if plan, err = m.PlanTags(ctx, c.Tags); err != nil {
+				return err
+			}
+
+			printTagPlan(cmd.OutOrStdout(), plan)
+			if dryRun || !plan.HasChanges() {
+				return nil
+			}
+
+			if !yes {
+				approved, err := confirm(cmd.InOrStdin(), cmd.OutOrStdout(), "Apply the plan?")
+				if err != nil {
+					return err
+				}
+				if !approved {
+					return fmt.Errorf("plan was not approved, no tags were written")
+				}
+			}
+
+			plan.SetJournal(j)
+			if err = j.Record(plan); err != nil {
+				return err
+			}
+
+			if err = m.ApplyTags(ctx, plan); err != nil {
+				return fmt.Errorf("%w, run tag again to resume or untag to revert applied tags", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Tags applied, run untag --journal %s to revert them.\n", j.Path())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringToStringVarP(&tags, "tags", "t", nil, "tags to add on top of the ones from configuration file, i.e. sigs.k8s.io/cluster-api-provider-aws/cluster/name=owned")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the plan without writing any tags")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "apply the plan without asking for approval")
+	cmd.Flags().StringVar(&journalPath, "journal", defaultJournalPath, "file progress of the tagging run is recorded in")
+
+	return cmd
+}
+
+// printTagPlan prints tag changes of every resource followed by the summary.
+func printTagPlan(w io.Writer, plan *api.TagPlan) {
+	add, change := 0, 0
+	for _, resource := range plan.Resources {
+		fmt.Fprintf(w, "%s %s:\n", resource.Kind, resource.ID)
+		for _, c := range resource.Changes {
+			fmt.Fprintf(w, "  %s\n", c)
+
+			switch c.Action {
+			case api.TagActionAdd:
+				add++
+			case api.TagActionChange:
+				change++
+			}
+		}
+	}
+
+	for _, resource := range plan.Skipped {
+		fmt.Fprintf(w, "%s %s is skipped: %s\n", resource.Kind, resource.ID, resource.Reason)
+	}
+
+	fmt.Fprintf(w, "\nPlan: %d tags to add, %d to change on %d resources.\n", add, change, len(plan.Resources))
+}
+
+// confirm asks the question and returns true if the answer is yes.
+func confirm(in io.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%s Only 'yes' will be accepted: ", question)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	return strings.TrimSpace(answer) == "yes", nil
+}
+
+// checkJournal ensures that the journal was created for the same provider and tags.
+func checkJournal(j *journal.Journal, plan *api.TagPlan, provider api.ClusterProvider, tags map[string]string) error {
+	if plan.Provider != provider {
+		return fmt.Errorf("journal %s was created for %s, not %s", j.Path(), plan.Provider, provider)
+	}
+
+	if tags != nil && !reflect.DeepEqual(plan.Tags, tags) {
+		return fmt.Errorf("journal %s was created for different tags, revert it with untag or remove it first", j.Path())
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/journal"
+)
+
+func newUntagCommand(options *options) *cobra.Command {
+	var journalPath string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "untag",
+		Short: "Revert tags applied by the tag command",
+		Long: "Revert tags applied by the tag command using its journal.\n\n" +
+			"Tags that were added are deleted and tags that were overwritten get their previous values back.\n" +
+			"The journal is updated as tags are reverted, so that the command can be run again if it fails,\n" +
+			"and removed once all tags are reverted.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			j := journal.New(journalPath)
+			plan, err := j.Load()
+			if err != nil {
+				return err
+			}
+
+			if !plan.Applied() {
+				fmt.Fprintf(cmd.OutOrStdout(), "No applied tags found in %s.\n", j.Path())
+				return j.Remove()
+			}
+
+			c, err := options.config()
+			if err != nil {
+				return err
+			}
+
+			if err = checkJournal(j, plan, c.Provider, nil); err != nil {
+				return err
+			}
+
+			printTagRevert(cmd.OutOrStdout(), plan)
+			if !yes {
+				approved, err := confirm(cmd.InOrStdin(), cmd.OutOrStdout(), "Revert the tags?")
+				if err != nil {
+					return err
+				}
+				if !approved {
+					return fmt.Errorf("revert was not approved, no tags were changed")
+				}
+			}
+
+			ctx, cancel := options.context(cmd.Context())
+			defer cancel()
+
+			m, err := newMigrator(ctx, c)
+			if err != nil {
+				return err
+			}
+
+			plan.SetJournal(j)
+			if err = m.RemoveTags(ctx, plan); err != nil {
+				return fmt.Errorf("%w, run untag again to resume", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Tags reverted.")
+			return j.Remove()
+		},
+	}
+
+	cmd.Flags().StringVar(&journalPath, "journal", defaultJournalPath, "journal recorded by the tag command")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "revert the tags without asking for approval")
+
+	return cmd
+}
+
+// printTagRevert prints tags that are deleted or restored for every resource with applied tags.
+func printTagRevert(w io.Writer, plan *api.TagPlan) {
+	for _, resource := range plan.Resources {
+		if !resource.Applied() {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s %s:\n", resource.Kind, resource.ID)
+		for _, key := range resource.RemovedKeys() {
+			fmt.Fprintf(w, "  - %s\n", key)
+		}
+
+		restored := resource.RestoredTags()
+		keys := make([]string, 0, len(restored))
+		for key := range restored {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "  ~ %s=%s\n", key, restored[key])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/config"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+	"github.com/pluralsh/cluster-api-migration/pkg/schema"
+)
+
+func newUpgradeCommand() *cobra.Command {
+	var version string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "upgrade FILE...",
+		Short: "Rewrite values files written by older releases to the current apiVersion",
+		Long: "Rewrite values files written by older releases to the current apiVersion.\n\n" +
+			"Files without apiVersion were written before values were versioned and are read as " + api.UnversionedVersion + ".\n" +
+			"Files are rewritten in place, as JSON if their extension is .json and as YAML otherwise. Files with keys\n" +
+			"that are not part of the values, i.e. other settings of the chart, are not rewritten, as the keys would be lost.\n" +
+			"With --api-version files are rewritten to an older version instead, i.e. for a chart that does not accept\n" +
+			"the current one yet.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, path := range args {
+				if err := upgradeValues(cmd, path, version, dryRun); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "api-version", api.Version, fmt.Sprintf("apiVersion to rewrite the files to, one of: %s", strings.Join(api.Versions(), ", ")))
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print rewritten values instead of writing them to the files")
+
+	return cmd
+}
+
+func upgradeValues(cmd *cobra.Command, path, version string, dryRun bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values, from, err := api.DecodeValuesStrict(data)
+	if err != nil {
+		return fmt.Errorf("cannot rewrite values %s: %w", path, err)
+	}
+
+	if err = schema.Validate(values); err != nil {
+		return fmt.Errorf("cannot rewrite values %s: %w", path, err)
+	}
+
+	converted, err := api.ConvertValues(values, version)
+	if err != nil {
+		return err
+	}
+
+	format := config.OutputFormatYAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = config.OutputFormatJSON
+	}
+
+	printer, err := resources.NewPrinter(format)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		return printer.Print(cmd.OutOrStdout(), converted)
+	}
+
+	if from == version {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is already %s\n", path, version)
+		return nil
+	}
+
+	if err = printer.PrintFile(path, converted); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s rewritten from %s to %s\n", path, from, version)
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/schema"
+)
+
+func newValidateCommand(options *options) *cobra.Command {
+	var valuesPath, chartSchema string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate configuration without connecting to the cloud provider",
+		Long: "Validate configuration without connecting to the cloud provider.\n\n" +
+			"With --values a values file, i.e. written by convert earlier, is validated instead against the schema\n" +
+			"of the values types and, if --chart-schema is set, against values.schema.json of the chart.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(valuesPath) > 0 {
+				// The file is validated as it is, so that unknown fields are reported instead of being dropped.
+				data, err := os.ReadFile(valuesPath)
+				if err != nil {
+					return err
+				}
+
+				values := map[string]interface{}{}
+				if err = yaml.Unmarshal(data, &values); err != nil {
+					return fmt.Errorf("invalid values file %s: %w", valuesPath, err)
+				}
+
+				if version, _ := values["apiVersion"].(string); version != api.Version {
+					return fmt.Errorf("%s values are not %s, rewrite them with the upgrade command first", valuesPath, api.Version)
+				}
+
+				// Cluster and workers accept sections of registered providers, so their other fields are checked by decoding.
+				if _, _, err = api.DecodeValuesStrict(data); err != nil {
+					return fmt.Errorf("invalid values file %s: %w", valuesPath, err)
+				}
+
+				if err = validateValues(values, chartSchema); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%s values are valid\n", valuesPath)
+				return nil
+			}
+
+			c, err := options.config()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s configuration is valid\n", c.Provider)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&valuesPath, "values", "", "values file to validate instead of the configuration")
+	cmd.Flags().StringVar(&chartSchema, "chart-schema", "", "values.schema.json of the cluster-api-cluster chart, or the chart directory, to validate values against")
+
+	return cmd
+}
+
+// validateValues checks values against the schema of the values types and against the chart schema if its path is set,
+// so that values the chart would reject are never written.
+func validateValues(values interface{}, chartSchema string) error {
+	if err := schema.Validate(values); err != nil {
+		return err
+	}
+
+	if len(chartSchema) == 0 {
+		return nil
+	}
+
+	return schema.ValidateChart(values, chartSchema)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/config"
+	"github.com/pluralsh/cluster-api-migration/pkg/diff"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+)
+
+func newVerifyCommand(options *options) *cobra.Command {
+	var managementKubeconfig, namespace, clusterName, format, rulesPath string
+	var overrideFiles []string
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Report drift between the cloud and Cluster API objects of the adopted cluster",
+		Long: "Report drift between the cloud and Cluster API objects of the adopted cluster.\n\n" +
+			"The cluster is converted from the cloud as with convert and compared with the values rebuilt from\n" +
+			"Cluster API objects in the management cluster, as with from-capi. Fields the objects do not set are\n" +
+			"not managed by Cluster API and are skipped. The command fails if any field differs. Rules and overrides\n" +
+			"of the configuration file, --rules and --overrides are applied to the converted cluster as convert does.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := options.output()
+			if err != nil {
+				return err
+			}
+
+			// Format of the configuration file is for values, changes are printed as a list unless -o is set.
+			output.Format = format
+			override(&output.Rules, rulesPath)
+			output.Overrides = append(output.Overrides, overrideFiles...)
+			if err = output.Validate(); err != nil {
+				return err
+			}
+			if format == config.OutputFormatSet {
+				return &api.InvalidConfigError{Err: fmt.Errorf("changes can be printed only as %s or %s", config.OutputFormatYAML, config.OutputFormatJSON)}
+			}
+
+			pipeline, err := newConversion(output)
+			if err != nil {
+				return err
+			}
+
+			c, err := options.config()
+			if err != nil {
+				return err
+			}
+
+			restConfig, namespace, err := managementCluster(managementKubeconfig, namespace)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := options.context(cmd.Context())
+			defer cancel()
+
+			m, err := newMigrator(ctx, c)
+			if err != nil {
+				return err
+			}
+
+			actual, _, err := pipeline.convert(ctx, m)
+			if err != nil {
+				return err
+			}
+
+			fallback(&clusterName, actual.Cluster.Name)
+			objects, err := manifests.Read(ctx, restConfig, namespace, clusterName)
+			if err != nil {
+				return err
+			}
+
+			managed, err := manifests.Values(objects, nil)
+			if err != nil {
+				return err
+			}
+			if managed.Provider != actual.Provider {
+				return fmt.Errorf("cluster %s/%s is managed by provider %s, not %s", namespace, clusterName, managed.Provider, actual.Provider)
+			}
+
+			unmanaged, err := manifests.Unmanaged(actual)
+			if err != nil {
+				return err
+			}
+
+			changes, err := diff.Drift(managed, actual, unmanaged...)
+			if err != nil {
+				return err
+			}
+
+			if len(format) > 0 {
+				if err = writeOutput(cmd.OutOrStdout(), output, changes); err != nil {
+					return err
+				}
+			} else {
+				for _, change := range changes {
+					fmt.Fprintln(cmd.OutOrStdout(), change)
+				}
+			}
+
+			if len(changes) > 0 {
+				return fmt.Errorf("cluster %s has drifted from Cluster API objects in %d fields", clusterName, len(changes))
+			}
+
+			if len(format) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Cluster %s matches Cluster API objects.\n", clusterName)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&managementKubeconfig, "management-kubeconfig", "", "path to the kubeconfig of the management cluster, defaults to KUBECONFIG")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "namespace of the Cluster (default namespace of the kubeconfig context)")
+	cmd.Flags().StringVar(&clusterName, "cluster", "", "name of the Cluster (default name of the converted cluster)")
+	cmd.Flags().StringVarP(&format, "output", "o", "", "print changes in the format, one of: yaml, json (default list of changes)")
+	cmd.Flags().StringVar(&rulesPath, "rules", "", "rules file that renames, drops or changes worker pools of the converted values")
+	cmd.Flags().StringSliceVar(&overrideFiles, "overrides", nil, "partial values files merged into the converted values in the given order, can be repeated")
+
+	return cmd
+}
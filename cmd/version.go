@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// Version information, overridden at build time with:
+// go build -ldflags "-X github.com/pluralsh/cluster-api-migration/cmd.version=... -X github.com/pluralsh/cluster-api-migration/cmd.commit=..."
+var (
+	version = "dev"
+	commit  = "none"
+)
+
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintf(cmd.OutOrStdout(), "version: %s, commit: %s, go: %s\n", version, commit, runtime.Version())
+			return nil
+		},
+	}
+}
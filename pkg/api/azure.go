@@ -4,7 +4,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// Following specs should match ones from plural-artifacts repository.
+// Following specs should match ones from plural-artifacts repository. Converted values are validated against
+// values.schema.json of the chart with --chart-schema, see pkg/schema.
 
 type AzureCloudSpec struct {
 	// Name of AzureClusterIdentity to be used when reconciling this cluster.
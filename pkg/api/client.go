@@ -0,0 +1,111 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// ClientOptions configure how cloud API clients connect, i.e. through a proxy in a restricted network
+// or to a local emulator with a self-signed certificate. Zero value keeps defaults of the SDKs.
+type ClientOptions struct {
+	// CABundle is a path to PEM encoded certificates that are trusted in addition to the system ones.
+	CABundle string `json:"caBundle,omitempty"`
+	// Proxy is URL of the proxy cloud APIs are reached through. Proxy environment variables are used if it is empty.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+func (o ClientOptions) IsZero() bool {
+	return len(o.CABundle) == 0 && len(o.Proxy) == 0
+}
+
+func (o ClientOptions) validate(errs *FieldErrors) {
+	if len(o.CABundle) > 0 {
+		if _, err := o.certPool(); err != nil {
+			errs.Add("caBundle", "%s", err)
+		}
+	}
+
+	if len(o.Proxy) > 0 {
+		validateURL(errs, "proxy", o.Proxy)
+	}
+}
+
+// TLSConfig returns TLS configuration that trusts the CA bundle. It returns nil if the CA bundle is not set.
+func (o ClientOptions) TLSConfig() (*tls.Config, error) {
+	if len(o.CABundle) == 0 {
+		return nil, nil
+	}
+
+	pool, err := o.certPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// ProxyURL returns parsed proxy URL. It returns nil if the proxy is not set.
+func (o ClientOptions) ProxyURL() (*url.URL, error) {
+	if len(o.Proxy) == 0 {
+		return nil, nil
+	}
+
+	return url.Parse(o.Proxy)
+}
+
+// HTTPClient returns a client that uses the proxy and trusts the CA bundle.
+func (o ClientOptions) HTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := o.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	proxy, err := o.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func (o ClientOptions) certPool() (*x509.CertPool, error) {
+	data, err := os.ReadFile(o.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("CA bundle %q cannot be read: %w", o.CABundle, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA bundle %q does not contain any PEM encoded certificate", o.CABundle)
+	}
+
+	return pool, nil
+}
+
+// validateURL ensures that the value is an absolute http or https URL, i.e. an endpoint or proxy.
+func validateURL(errs *FieldErrors, field, value string) {
+	u, err := url.Parse(value)
+	if err != nil {
+		errs.Add(field, "%q is not a valid URL: %s", value, err)
+		return
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		errs.Add(field, "%q is not an absolute http or https URL", value)
+	}
+}
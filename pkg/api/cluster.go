@@ -1,5 +1,10 @@
 package api
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 type ClusterProvider string
 type ClusterType string
 
@@ -13,10 +18,12 @@ const (
 )
 
 type Values struct {
-	Provider ClusterProvider `json:"provider"`
-	Type     ClusterType     `json:"type"`
-	Cluster  Cluster         `json:"cluster"`
-	Workers  Workers         `json:"workers"`
+	// APIVersion of the values, Version for values written by this release.
+	APIVersion string          `json:"apiVersion"`
+	Provider   ClusterProvider `json:"provider"`
+	Type       ClusterType     `json:"type"`
+	Cluster    Cluster         `json:"cluster"`
+	Workers    Workers         `json:"workers"`
 }
 
 type ClusterAPI struct {
@@ -26,11 +33,16 @@ type ClusterAPI struct {
 	Workers  Workers `json:"workers"`
 }
 
+// Cluster holds settings of the cluster. Sections of providers without a field in CloudSpec are kept in
+// CloudSpec.Providers and encoded next to the built-in ones.
+//
+// +kubebuilder:pruning:PreserveUnknownFields
 type Cluster struct {
 	Name              string   `json:"name"`
 	PodCIDRBlocks     []string `json:"podCidrBlocks,omitempty"`
 	ServiceCIDRBlocks []string `json:"serviceCidrBlocks,omitempty"`
-	KubernetesVersion string   `json:"kubernetesVersion"`
+	// KubernetesVersion is prefixed with v like in Cluster API, i.e. v1.27 or v1.27.3-gke.100, see KubernetesVersion.
+	KubernetesVersion string `json:"kubernetesVersion"`
 	CloudSpec         `json:",inline"`
 }
 
@@ -38,8 +50,14 @@ type CloudSpec struct {
 	AWSCloudSpec   *AWSCloudSpec   `json:"aws,omitempty"`
 	AzureCloudSpec *AzureCloudSpec `json:"azure,omitempty"`
 	GCPCloudSpec   *GCPCloudSpec   `json:"gcp,omitempty"`
+	// Providers holds sections of other registered providers by their names, i.e. vsphere.
+	Providers map[ClusterProvider]json.RawMessage `json:"-"`
 }
 
+// Workers holds worker pools of the cluster. Pools of providers without a field in WorkersSpec are kept in
+// WorkersSpec.Providers and encoded next to the built-in ones.
+//
+// +kubebuilder:pruning:PreserveUnknownFields
 type Workers struct {
 	WorkersSpec `json:",inline"`
 }
@@ -48,4 +66,34 @@ type WorkersSpec struct {
 	AWSWorkers   *AWSWorkers   `json:"aws,omitempty"`
 	AzureWorkers *AzureWorkers `json:"azure,omitempty"`
 	GCPWorkers   *GCPWorkers   `json:"gcp,omitempty"`
+	// Providers holds pools of other registered providers by their names, i.e. vsphere.
+	Providers map[ClusterProvider]json.RawMessage `json:"-"`
+}
+
+// ClusterValues is the part of Values without workers, so that node pools can be kept in a separate file.
+type ClusterValues struct {
+	APIVersion string          `json:"apiVersion"`
+	Provider   ClusterProvider `json:"provider"`
+	Type       ClusterType     `json:"type"`
+	Cluster    Cluster         `json:"cluster"`
+}
+
+// WorkersValues is the part of Values with workers only.
+type WorkersValues struct {
+	Workers Workers `json:"workers"`
+}
+
+// Split returns cluster and workers parts of the values. Both can be passed to the chart together.
+func (v *Values) Split() (*ClusterValues, *WorkersValues) {
+	return &ClusterValues{APIVersion: v.APIVersion, Provider: v.Provider, Type: v.Type, Cluster: v.Cluster}, &WorkersValues{Workers: v.Workers}
+}
+
+// KubernetesVersion returns version in the format of Cluster.KubernetesVersion. Cloud providers report versions
+// without the v prefix, so converting and reading Cluster API objects both go through it.
+func KubernetesVersion(version string) string {
+	if len(version) == 0 || strings.HasPrefix(version, "v") {
+		return version
+	}
+
+	return "v" + version
 }
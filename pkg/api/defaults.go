@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LatestChartProfile names the default worker pools of the cluster-api-cluster chart this release was tested with.
+const LatestChartProfile = "latest"
+
+// DefaultWorkers are names of worker pools the cluster-api-cluster chart creates unless values set them to null.
+type DefaultWorkers map[ClusterProvider][]string
+
+// chartProfiles are default worker pools of the chart versions, taken from bootstrap/helm/cluster-api-cluster/values.yaml.
+// Add a profile named after the chart version when the chart changes its default pools.
+var chartProfiles = map[string]DefaultWorkers{
+	LatestChartProfile: {
+		ClusterProviderAWS:   {"small-burst-on-demand", "medium-burst-on-demand", "large-burst-on-demand"},
+		ClusterProviderAzure: {"lsod", "lsspot", "msod", "msspot", "ssod", "ssspot"},
+		ClusterProviderGCP:   {"small-burst-on-demand", "medium-burst-on-demand", "large-burst-on-demand"},
+	},
+}
+
+// ChartProfile returns default worker pools of the built-in chart profile.
+func ChartProfile(name string) (DefaultWorkers, error) {
+	profile, ok := chartProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown chart profile %q, use one of %v", name, ChartProfiles())
+	}
+
+	return profile, nil
+}
+
+// ChartProfiles returns sorted names of the built-in chart profiles.
+func ChartProfiles() []string {
+	result := make([]string, 0, len(chartProfiles))
+	for name := range chartProfiles {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// LatestDefaultWorkers returns default worker pools of the LatestChartProfile.
+func LatestDefaultWorkers() DefaultWorkers {
+	return chartProfiles[LatestChartProfile]
+}
+
+// SetDefaultWorkers sets default pools of the chart that the cluster does not have to null, so that they are not
+// created during migration. Null pools of other defaults, i.e. of an older chart, are removed first.
+func (v *Values) SetDefaultWorkers(defaults DefaultWorkers) {
+	switch v.Provider {
+	case ClusterProviderAWS:
+		if v.Workers.AWSWorkers == nil {
+			v.Workers.AWSWorkers = &AWSWorkers{}
+		}
+		setDefaultWorkers(*v.Workers.AWSWorkers, defaults[v.Provider])
+	case ClusterProviderAzure:
+		if v.Workers.AzureWorkers == nil {
+			v.Workers.AzureWorkers = &AzureWorkers{}
+		}
+		setDefaultWorkers(*v.Workers.AzureWorkers, defaults[v.Provider])
+	case ClusterProviderGCP:
+		if v.Workers.GCPWorkers == nil {
+			v.Workers.GCPWorkers = &GCPWorkers{}
+		}
+		setDefaultWorkers(*v.Workers.GCPWorkers, defaults[v.Provider])
+	}
+}
+
+func setDefaultWorkers[W any](workers map[string]*W, names []string) {
+	for name, worker := range workers {
+		if worker == nil {
+			delete(workers, name)
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := workers[name]; !ok {
+			workers[name] = nil
+		}
+	}
+}
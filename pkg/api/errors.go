@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+)
+
+// NotFoundError is returned when the cluster or one of its resources does not exist.
+type NotFoundError struct {
+	// Resource describes what was requested, i.e. EKS cluster test.
+	Resource string
+	Err      error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.Resource, e.Err)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// PermissionDeniedError is returned when credentials are missing or do not allow to access the resource.
+type PermissionDeniedError struct {
+	// Resource describes what was requested, i.e. EKS cluster test.
+	Resource string
+	Err      error
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("permission denied to %s: %s", e.Resource, e.Err)
+}
+
+func (e *PermissionDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// ThrottledError is returned when the cloud provider rejected the request due to rate limits or quotas.
+// Request can be retried later.
+type ThrottledError struct {
+	// Resource describes what was requested, i.e. EKS cluster test.
+	Resource string
+	Err      error
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("request to %s was throttled: %s", e.Resource, e.Err)
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidConfigError is returned when configuration fails validation. Err is usually FieldErrors.
+type InvalidConfigError struct {
+	Err error
+}
+
+func (e *InvalidConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorClass is the category of a cloud provider error used by NewError.
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown leaves the error as it is.
+	ErrorClassUnknown ErrorClass = iota
+	ErrorClassNotFound
+	ErrorClassPermissionDenied
+	ErrorClassThrottled
+)
+
+// NewError wraps err into the typed error matching class. Unknown errors are returned unchanged.
+func NewError(class ErrorClass, resource string, err error) error {
+	switch class {
+	case ErrorClassNotFound:
+		return &NotFoundError{Resource: resource, Err: err}
+	case ErrorClassPermissionDenied:
+		return &PermissionDeniedError{Resource: resource, Err: err}
+	case ErrorClassThrottled:
+		return &ThrottledError{Resource: resource, Err: err}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+// ErrReplay is returned by operations that would change cloud resources while replaying recorded responses.
+var ErrReplay = errors.New("cloud resources cannot be changed when replaying recorded responses")
+
+// Fixture bundles raw responses of the cloud APIs a cluster was converted from,
+// so that the conversion can be reproduced without credentials and network access.
+type Fixture struct {
+	Provider ClusterProvider `json:"provider"`
+	// Configuration the responses were recorded with.
+	Configuration json.RawMessage `json:"configuration"`
+	// Responses are stored in a format defined by the provider.
+	Responses json.RawMessage `json:"responses"`
+}
+
+// NewFixture bundles the responses with the configuration. Credentials in URLs of the configuration,
+// i.e. user:pass@ in the proxy, are removed, so that the fixture can be shared.
+func NewFixture(provider ClusterProvider, configuration Validator, responses interface{}) (*Fixture, error) {
+	c, err := redactConfiguration(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := json.Marshal(responses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fixture{Provider: provider, Configuration: c, Responses: r}, nil
+}
+
+// redactConfiguration returns the configuration as JSON with user info removed from every URL.
+func redactConfiguration(configuration Validator) ([]byte, error) {
+	data, err := json.Marshal(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redactURLs(document))
+}
+
+func redactURLs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if u, err := url.Parse(v); err == nil && len(u.Host) > 0 && u.User != nil {
+			u.User = nil
+			return u.String()
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = redactURLs(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactURLs(item)
+		}
+	}
+
+	return value
+}
+
+// Recorder is implemented by migrators and accessors that keep raw responses read from the cloud APIs.
+type Recorder interface {
+	// Fixture returns responses read so far.
+	Fixture() (*Fixture, error)
+}
@@ -88,7 +88,7 @@ type GCPWorkerSpec struct {
 	Management       *GCPWorkerManagement `json:"management,omitempty"`
 	KubernetesLabels *Labels              `json:"kubernetesLabels,omitempty"`
 	AdditionalLabels *Labels              `json:"additionalLabels,omitempty"`
-	KubernetesTaints *Taints              `json:"kubernetesTaints,omitempty"`
+	KubernetesTaints *GCPTaints           `json:"kubernetesTaints,omitempty"`
 	ProviderIDList   []string             `json:"providerIDList,omitempty"`
 	MachineType      string               `json:"machineType,omitempty"`
 	DiskSizeGb       int32                `json:"diskSizeGb,omitempty"`
@@ -98,6 +98,33 @@ type GCPWorkerSpec struct {
 	Spot             bool                 `json:"spot,omitempty"`
 }
 
+// GCPTaintEffect is the effect for a Kubernetes taint of GKE node pool. Unlike TaintEffect, it uses
+// the Kubernetes names GCPManagedMachinePool expects.
+type GCPTaintEffect string
+
+const (
+	GCPTaintEffectNoSchedule       = GCPTaintEffect("NoSchedule")
+	GCPTaintEffectNoExecute        = GCPTaintEffect("NoExecute")
+	GCPTaintEffectPreferNoSchedule = GCPTaintEffect("PreferNoSchedule")
+)
+
+// GCPTaint defines the specs for a Kubernetes taint of GKE node pool.
+type GCPTaint struct {
+	// Effect specifies the effect for the taint
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=NoSchedule;NoExecute;PreferNoSchedule
+	Effect GCPTaintEffect `json:"effect"`
+	// Key is the key of the taint
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+	// Value is the value of the taint
+	// +kubebuilder:validation:Required
+	Value string `json:"value"`
+}
+
+// GCPTaints is an array of GCPTaint.
+type GCPTaints []GCPTaint
+
 type GCPWorkerScaling struct {
 	MaxCount int32 `json:"maxCount"`
 	MinCount int32 `json:"minCount"`
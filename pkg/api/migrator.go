@@ -1,63 +1,208 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"regexp"
 )
 
+var (
+	awsClusterName      = regexp.MustCompile(`^[0-9A-Za-z][A-Za-z0-9\-_]{0,99}$`)
+	awsRegion           = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+	azureSubscriptionID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	gcpProject          = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	gcpLocation         = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+(-[a-z])?$`)
+)
+
+// Configuration holds configuration of the providers by their names. Every provider, built-in or not, is configured
+// with the type returned by NewConfiguration of its registration.
 type Configuration struct {
-	*AWSConfiguration
-	*AzureConfiguration
-	*GCPConfiguration
-	*KindConfiguration
+	Providers map[ClusterProvider]Validator
+}
+
+// ProviderConfiguration returns configuration of the given provider. It fails if the provider is not registered
+// or if its configuration is not set.
+func (config *Configuration) ProviderConfiguration(provider ClusterProvider) (Validator, error) {
+	if _, ok := LookupProvider(provider); !ok {
+		return nil, fmt.Errorf("unsupported provider %q", provider)
+	}
+
+	result, ok := config.Providers[provider]
+	if !ok || result == nil {
+		return nil, fmt.Errorf("%s configuration cannot be empty, ensure that it is set", provider)
+	}
+
+	return result, nil
 }
 
 type AWSConfiguration struct {
-	ClusterName string
-	Region      string
+	ClusterName string       `json:"clusterName"`
+	Region      string       `json:"region"`
+	Endpoints   AWSEndpoints `json:"endpoints,omitempty"`
+	ClientOptions
+}
+
+// AWSEndpoints override endpoints of the AWS APIs, i.e. with VPC endpoints or a LocalStack URL.
+// Empty endpoints are resolved from the region.
+type AWSEndpoints struct {
+	EC2 string `json:"ec2,omitempty"`
+	EKS string `json:"eks,omitempty"`
+}
+
+func (config *AWSConfiguration) Validate() error {
+	errs := FieldErrors{}
+	if len(config.ClusterName) == 0 {
+		errs.Add("clusterName", "cluster name cannot be empty, ensure that it is set")
+	} else if !awsClusterName.MatchString(config.ClusterName) {
+		errs.Add("clusterName", "cluster name %q is not a valid EKS cluster name", config.ClusterName)
+	}
+
+	if len(config.Region) == 0 {
+		errs.Add("region", "region cannot be empty, ensure that it is set")
+	} else if !awsRegion.MatchString(config.Region) {
+		errs.Add("region", "region %q is not a valid AWS region, i.e. eu-central-1", config.Region)
+	}
+
+	if len(config.Endpoints.EC2) > 0 {
+		validateURL(&errs, "endpoints.ec2", config.Endpoints.EC2)
+	}
+	if len(config.Endpoints.EKS) > 0 {
+		validateURL(&errs, "endpoints.eks", config.Endpoints.EKS)
+	}
+	config.ClientOptions.validate(&errs)
+
+	return errs.ErrorOrNil()
 }
 
 type KindConfiguration struct {
-	ClusterName string
+	ClusterName string `json:"clusterName"`
+}
+
+func (config *KindConfiguration) Validate() error {
+	errs := FieldErrors{}
+	if len(config.ClusterName) == 0 {
+		errs.Add("clusterName", "cluster name cannot be empty, ensure that it is set")
+	}
+
+	return errs.ErrorOrNil()
 }
 
 type AzureConfiguration struct {
-	SubscriptionID string
-	ResourceGroup  string
-	Name           string
+	SubscriptionID string         `json:"subscriptionID"`
+	ResourceGroup  string         `json:"resourceGroup"`
+	Name           string         `json:"name"`
+	Endpoints      AzureEndpoints `json:"endpoints,omitempty"`
+	ClientOptions
+}
+
+// AzureEndpoints override endpoints of the Azure APIs, i.e. with a private link URL.
+// Empty endpoints default to the Azure public cloud.
+type AzureEndpoints struct {
+	ResourceManager string `json:"resourceManager,omitempty"`
 }
 
 func (config *AzureConfiguration) Validate() error {
+	errs := FieldErrors{}
 	if len(config.SubscriptionID) == 0 {
-		return fmt.Errorf("subscription ID cannot be empty, ensure that it is set")
+		errs.Add("subscriptionID", "subscription ID cannot be empty, ensure that it is set")
+	} else if !azureSubscriptionID.MatchString(config.SubscriptionID) {
+		errs.Add("subscriptionID", "subscription ID %q is not a valid GUID", config.SubscriptionID)
 	}
 
 	if len(config.ResourceGroup) == 0 {
-		return fmt.Errorf("resource group cannot be empty, ensure that it is set")
+		errs.Add("resourceGroup", "resource group cannot be empty, ensure that it is set")
 	}
 
 	if len(config.Name) == 0 {
-		return fmt.Errorf("name cannot be empty, ensure that it is set")
+		errs.Add("name", "name cannot be empty, ensure that it is set")
 	}
 
-	return nil
+	if len(config.Endpoints.ResourceManager) > 0 {
+		validateURL(&errs, "endpoints.resourceManager", config.Endpoints.ResourceManager)
+	}
+	config.ClientOptions.validate(&errs)
+
+	return errs.ErrorOrNil()
 }
 
 type GCPConfiguration struct {
-	Project        string
-	Region         string
-	Name           string
-	KubeconfigPath string
+	Project        string       `json:"project"`
+	Region         string       `json:"region"`
+	Name           string       `json:"name"`
+	KubeconfigPath string       `json:"kubeconfigPath,omitempty"`
+	Endpoints      GCPEndpoints `json:"endpoints,omitempty"`
+	ClientOptions
+}
+
+// GCPEndpoints override endpoints of the Google Cloud APIs, i.e. with a Private Service Connect URL or an emulator.
+// Compute endpoint includes the API path, i.e. https://compute.example.com/compute/v1/. Plain http container
+// endpoint is dialed without TLS and authentication. Empty endpoints use the defaults.
+type GCPEndpoints struct {
+	Container string `json:"container,omitempty"`
+	Compute   string `json:"compute,omitempty"`
+}
+
+func (config *GCPConfiguration) Validate() error {
+	errs := FieldErrors{}
+	if len(config.Project) == 0 {
+		errs.Add("project", "project cannot be empty, ensure that it is set")
+	} else if !gcpProject.MatchString(config.Project) {
+		errs.Add("project", "project %q is not a valid GCP project ID", config.Project)
+	}
+
+	if len(config.Region) == 0 {
+		errs.Add("region", "region cannot be empty, ensure that it is set")
+	} else if !gcpLocation.MatchString(config.Region) {
+		errs.Add("region", "region %q is not a valid GCP region or zone, i.e. europe-central2", config.Region)
+	}
+
+	if len(config.Name) == 0 {
+		errs.Add("name", "name cannot be empty, ensure that it is set")
+	}
+
+	// Empty path falls back to in-cluster configuration.
+	if len(config.KubeconfigPath) > 0 {
+		if info, err := os.Stat(config.KubeconfigPath); err != nil {
+			errs.Add("kubeconfigPath", "kubeconfig file %q cannot be read: %s", config.KubeconfigPath, err)
+		} else if info.IsDir() {
+			errs.Add("kubeconfigPath", "kubeconfig path %q is a directory", config.KubeconfigPath)
+		}
+	}
+
+	if len(config.Endpoints.Container) > 0 {
+		validateURL(&errs, "endpoints.container", config.Endpoints.Container)
+	}
+	if len(config.Endpoints.Compute) > 0 {
+		validateURL(&errs, "endpoints.compute", config.Endpoints.Compute)
+	}
+	config.ClientOptions.validate(&errs)
+
+	return errs.ErrorOrNil()
 }
 
 type Migrator interface {
-	Convert() (*Values, error)
-	AddTags(tags map[string]string) error
+	// Convert reads the existing cluster and returns chart values together with the report
+	// of values that have to be checked by hand. Default pools of the LatestChartProfile that the cluster
+	// does not have are set to null, Values.SetDefaultWorkers replaces them with pools of another chart.
+	Convert(ctx context.Context) (*Values, *ConversionReport, error)
+	// PlanTags compares tags with the ones already set on the cluster resources without changing anything.
+	PlanTags(ctx context.Context, tags map[string]string) (*TagPlan, error)
+	// ApplyTags writes added and changed tags from the plan and marks them as applied.
+	ApplyTags(ctx context.Context, plan *TagPlan) error
+	// AddTags plans tags and applies the plan right away. The returned plan can be passed to RemoveTags,
+	// it is returned also when applying fails, so that partially applied tags can be reverted.
+	AddTags(ctx context.Context, tags map[string]string) (*TagPlan, error)
+	// RemoveTags reverts applied tags from the plan. Added tags are deleted and overwritten ones get their previous values back.
+	RemoveTags(ctx context.Context, plan *TagPlan) error
 }
 
 type ClusterAccessor interface {
-	GetCluster() (*Cluster, error)
-	GetWorkers() (*Workers, error)
-	AddClusterTags(Tags map[string]string) error
-	AddMachinePollsTags(Tags map[string]string) error
-	AddVirtualNetworkTags(tags map[string]string) error
+	GetCluster(ctx context.Context, report *ConversionReport) (*Cluster, error)
+	GetWorkers(ctx context.Context, report *ConversionReport) (*Workers, error)
+	PlanClusterTags(ctx context.Context, tags map[string]string, plan *TagPlan) error
+	PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *TagPlan) error
+	PlanVirtualNetworkTags(ctx context.Context, tags map[string]string, plan *TagPlan) error
+	ApplyTags(ctx context.Context, plan *TagPlan) error
+	RemoveTags(ctx context.Context, plan *TagPlan) error
 }
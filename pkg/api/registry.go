@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+)
+
+// ReplayFactory creates migrator that converts responses recorded in Fixture.Responses instead of calling the cloud APIs.
+type ReplayFactory func(config Validator, responses []byte) (Migrator, error)
+
+// ProviderFactory creates migrator for the configuration returned by ProviderRegistration.NewConfiguration.
+// The configuration is validated before the factory is called.
+type ProviderFactory func(ctx context.Context, config Validator) (Migrator, error)
+
+// ManifestRenderer renders Cluster API objects of the provider from the converted values. Values that cannot be
+// expressed in the objects are added to the report.
+type ManifestRenderer func(values *Values, report *ConversionReport) ([]runtime.Object, error)
+
+// ClusterObjects are Cluster API objects of a single cluster read from a management cluster.
+// Provider objects are unstructured, as their API types are not vendored for every provider.
+type ClusterObjects struct {
+	Cluster        *clusterv1.Cluster
+	ControlPlane   *unstructured.Unstructured
+	Infrastructure *unstructured.Unstructured
+	MachinePools   []MachinePoolObjects
+}
+
+// MachinePoolObjects are MachinePool of the cluster and the provider managed machine pool it references.
+type MachinePoolObjects struct {
+	MachinePool    *expv1.MachinePool
+	Infrastructure *unstructured.Unstructured
+}
+
+// ManifestReader rebuilds values from Cluster API objects of the provider, the reverse of ManifestRenderer.
+// Settings of the objects that cannot be expressed in the values are added to the report.
+type ManifestReader func(objects *ClusterObjects, report *ConversionReport) (*Values, error)
+
+// ClusterClassSupport describes how objects rendered by ProviderRegistration.RenderManifests become templates
+// of a ClusterClass. Template kinds are named after the rendered kinds with the Template suffix.
+type ClusterClassSupport struct {
+	// ClusterVariables are control plane spec fields that differ between clusters of the class, i.e. location.
+	// They are replaced with variables set in the Cluster topology.
+	ClusterVariables []string
+	// PoolVariables are managed machine pool spec fields that differ between pools of the same class, i.e. name.
+	// Pools whose specs differ only in these fields share a class and set the fields with variable overrides.
+	PoolVariables []string
+	// BootstrapTemplate returns bootstrap config template machine pool classes reference. ClusterClass requires it
+	// also for managed machine pools, which are bootstrapped by the cloud provider.
+	BootstrapTemplate func(name string) runtime.Object
+}
+
+// ProviderRegistration describes cluster provider that can be migrated. Provider packages register
+// themselves in init, so that new providers can be added without changing the core packages.
+type ProviderRegistration struct {
+	Name ClusterProvider
+	// NewConfiguration returns empty configuration the provider section of the configuration file is decoded into.
+	// It has to be a pointer to a struct with JSON tags.
+	NewConfiguration func() Validator
+	NewMigrator      ProviderFactory
+	// NewReplayMigrator is optional, providers without it cannot replay recorded responses.
+	NewReplayMigrator ReplayFactory
+	// RenderManifests is optional, providers without it can only output chart values.
+	RenderManifests ManifestRenderer
+	// ClusterClass is optional, it requires RenderManifests and templates of the rendered objects in the provider API.
+	ClusterClass *ClusterClassSupport
+	// ReadManifests is optional, providers without it cannot rebuild values from Cluster API objects.
+	ReadManifests ManifestReader
+	// ControlPlaneKind identifies clusters of the provider in a management cluster, it is required by ReadManifests.
+	ControlPlaneKind schema.GroupKind
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[ClusterProvider]ProviderRegistration{}
+)
+
+// RegisterProvider makes provider available by its name. It panics if the registration is incomplete
+// or if the provider with the same name was already registered.
+func RegisterProvider(registration ProviderRegistration) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if len(registration.Name) == 0 || registration.NewConfiguration == nil || registration.NewMigrator == nil {
+		panic(fmt.Sprintf("incomplete registration of provider %q", registration.Name))
+	}
+
+	if _, ok := providers[registration.Name]; ok {
+		panic(fmt.Sprintf("provider %q is already registered", registration.Name))
+	}
+
+	providers[registration.Name] = registration
+}
+
+// LookupProvider returns registration of the provider with the given name.
+func LookupProvider(name ClusterProvider) (ProviderRegistration, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	registration, ok := providers[name]
+	return registration, ok
+}
+
+// LookupProviderByControlPlane returns registration of the provider that reads clusters with the given control plane.
+func LookupProviderByControlPlane(kind schema.GroupKind) (ProviderRegistration, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	for _, registration := range providers {
+		if registration.ReadManifests != nil && registration.ControlPlaneKind == kind {
+			return registration, true
+		}
+	}
+
+	return ProviderRegistration{}, false
+}
+
+// Providers returns sorted names of all registered providers.
+func Providers() []ClusterProvider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	result := make([]ClusterProvider, 0, len(providers))
+	for name := range providers {
+		result = append(result, name)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+
+	return result
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConversionWarningKind describes how converted value differs from the existing cluster.
+type ConversionWarningKind string
+
+const (
+	// ConversionWarningDefaulted means that the value was not read from the cluster and a default was used instead.
+	ConversionWarningDefaulted = ConversionWarningKind("defaulted")
+	// ConversionWarningDropped means that the cluster has a setting that is not present in the values.
+	ConversionWarningDropped = ConversionWarningKind("dropped")
+	// ConversionWarningApproximated means that the value was derived from the cluster, but may not match it exactly.
+	ConversionWarningApproximated = ConversionWarningKind("approximated")
+	// ConversionWarningOverridden means that the value was changed by an override file after conversion.
+	ConversionWarningOverridden = ConversionWarningKind("overridden")
+	// ConversionWarningTransformed means that the value was changed by a rule of the rules file after conversion.
+	ConversionWarningTransformed = ConversionWarningKind("transformed")
+)
+
+// ConversionWarning points to a value that has to be checked by hand before applying the chart.
+type ConversionWarning struct {
+	// Path is the JSON path of the value in Values, i.e. cluster.aws.endpointAccess.public.
+	Path   string                `json:"path"`
+	Kind   ConversionWarningKind `json:"kind"`
+	Reason string                `json:"reason"`
+}
+
+func (w ConversionWarning) String() string {
+	return fmt.Sprintf("%s %s: %s", w.Kind, w.Path, w.Reason)
+}
+
+// ConversionReport lists all values that were defaulted, dropped or approximated during conversion.
+// All methods are safe to call on nil report, so converters can be used without it.
+type ConversionReport struct {
+	Warnings []ConversionWarning `json:"warnings"`
+}
+
+func NewConversionReport() *ConversionReport {
+	return &ConversionReport{Warnings: []ConversionWarning{}}
+}
+
+func (r *ConversionReport) add(kind ConversionWarningKind, path, format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+
+	r.Warnings = append(r.Warnings, ConversionWarning{
+		Path:   path,
+		Kind:   kind,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *ConversionReport) Defaulted(path, format string, args ...interface{}) {
+	r.add(ConversionWarningDefaulted, path, format, args...)
+}
+
+func (r *ConversionReport) Dropped(path, format string, args ...interface{}) {
+	r.add(ConversionWarningDropped, path, format, args...)
+}
+
+func (r *ConversionReport) Approximated(path, format string, args ...interface{}) {
+	r.add(ConversionWarningApproximated, path, format, args...)
+}
+
+func (r *ConversionReport) Overridden(path, format string, args ...interface{}) {
+	r.add(ConversionWarningOverridden, path, format, args...)
+}
+
+func (r *ConversionReport) Transformed(path, format string, args ...interface{}) {
+	r.add(ConversionWarningTransformed, path, format, args...)
+}
+
+// Empty returns true if conversion did not produce any warnings.
+func (r *ConversionReport) Empty() bool {
+	return r == nil || len(r.Warnings) == 0
+}
+
+// Path joins JSON path elements, i.e. Path("workers", "aws", name, "spec").
+func Path(elements ...string) string {
+	return strings.Join(elements, ".")
+}
+
+// Sort orders warnings by path, keeping the order of warnings with the same path, so that the report
+// does not depend on the order node pools were read in.
+func (r *ConversionReport) Sort() {
+	if r == nil {
+		return
+	}
+
+	sort.SliceStable(r.Warnings, func(i, j int) bool {
+		return r.Warnings[i].Path < r.Warnings[j].Path
+	})
+}
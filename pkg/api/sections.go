@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+func (c Cluster) MarshalJSON() ([]byte, error) {
+	// plain has the same fields as Cluster, but not its JSON methods.
+	type plain Cluster
+	return marshalSections(plain(c), c.Providers)
+}
+
+// UnmarshalJSON decodes sections of registered providers without a field in CloudSpec into CloudSpec.Providers
+// and fails on any other unknown field.
+func (c *Cluster) UnmarshalJSON(data []byte) error {
+	type plain Cluster
+	sections, err := unmarshalSections(data, (*plain)(c))
+	if err != nil {
+		return err
+	}
+
+	c.Providers = sections
+	return nil
+}
+
+func (w Workers) MarshalJSON() ([]byte, error) {
+	type plain Workers
+	return marshalSections(plain(w), w.Providers)
+}
+
+// UnmarshalJSON decodes pools of registered providers without a field in WorkersSpec into WorkersSpec.Providers
+// and fails on any other unknown field.
+func (w *Workers) UnmarshalJSON(data []byte) error {
+	type plain Workers
+	sections, err := unmarshalSections(data, (*plain)(w))
+	if err != nil {
+		return err
+	}
+
+	w.Providers = sections
+	return nil
+}
+
+// marshalSections encodes the struct and adds sections of the providers as its fields.
+func marshalSections(value interface{}, sections map[ClusterProvider]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil || len(sections) == 0 {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for provider, section := range sections {
+		if _, ok := fields[string(provider)]; ok {
+			return nil, fmt.Errorf("section of provider %s conflicts with a field of the same name", provider)
+		}
+		fields[string(provider)] = section
+	}
+
+	return json.Marshal(fields)
+}
+
+// unmarshalSections decodes fields of the target struct strictly and returns fields named after registered
+// providers as their sections. Any other unknown field is an error.
+func unmarshalSections(data []byte, target interface{}) (map[ClusterProvider]json.RawMessage, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	known := jsonFields(reflect.TypeOf(target).Elem())
+	var sections map[ClusterProvider]json.RawMessage
+	for key, value := range fields {
+		if known[key] {
+			continue
+		}
+
+		if _, ok := LookupProvider(ClusterProvider(key)); !ok {
+			return nil, fmt.Errorf("json: unknown field %q", key)
+		}
+
+		if sections == nil {
+			sections = map[ClusterProvider]json.RawMessage{}
+		}
+		sections[ClusterProvider(key)] = value
+		delete(fields, key)
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(target); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// jsonFields returns names of the JSON fields of the struct type, including fields of inlined structs.
+func jsonFields(t reflect.Type) map[string]bool {
+	result := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if (field.Anonymous && len(name) == 0) || strings.Contains(","+options+",", ",inline,") {
+			for inlined := range jsonFields(field.Type) {
+				result[inlined] = true
+			}
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		result[name] = true
+	}
+
+	return result
+}
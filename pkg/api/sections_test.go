@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+const testProvider = ClusterProvider("sections-test")
+
+type testConfiguration struct{}
+
+func (testConfiguration) Validate() error {
+	return nil
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:             testProvider,
+		NewConfiguration: func() Validator { return &testConfiguration{} },
+		NewMigrator: func(ctx context.Context, config Validator) (Migrator, error) {
+			return nil, nil
+		},
+	})
+}
+
+func TestSectionsOfRegisteredProvidersRoundTrip(t *testing.T) {
+	values := &Values{
+		APIVersion: Version,
+		Provider:   testProvider,
+		Cluster: Cluster{
+			Name:      "test",
+			CloudSpec: CloudSpec{Providers: map[ClusterProvider]json.RawMessage{testProvider: json.RawMessage(`{"datacenter":"dc1"}`)}},
+		},
+		Workers: Workers{
+			WorkersSpec: WorkersSpec{Providers: map[ClusterProvider]json.RawMessage{testProvider: json.RawMessage(`{"pool":{"replicas":3}}`)}},
+		},
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "sections-test:\n    datacenter: dc1") {
+		t.Errorf("cluster section is not encoded next to the built-in ones:\n%s", data)
+	}
+
+	decoded, _, err := DecodeValuesStrict(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(decoded.Cluster.Providers[testProvider]); got != `{"datacenter":"dc1"}` {
+		t.Errorf("cluster section = %s", got)
+	}
+	if got := string(decoded.Workers.Providers[testProvider]); got != `{"pool":{"replicas":3}}` {
+		t.Errorf("workers section = %s", got)
+	}
+}
+
+func TestUnknownClusterFieldsAreRejected(t *testing.T) {
+	_, _, err := DecodeValues([]byte("apiVersion: " + Version + "\ncluster:\n  name: test\n  vsphere: {}\n"))
+	if err == nil || !strings.Contains(err.Error(), `unknown field "vsphere"`) {
+		t.Errorf("error = %v, want unknown field vsphere", err)
+	}
+}
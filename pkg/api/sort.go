@@ -0,0 +1,209 @@
+package api
+
+import (
+	"sort"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+)
+
+// Sort orders slices of the values by a defined key, so that values converted from an unchanged cluster
+// are printed the same on every run. Lists where the order is significant, i.e. CIDR blocks of the cluster
+// network and environment of the VPC CNI, are kept as they are.
+func (v *Values) Sort() {
+	if v == nil {
+		return
+	}
+
+	if spec := v.Cluster.AWSCloudSpec; spec != nil {
+		spec.sort()
+	}
+	if spec := v.Cluster.AzureCloudSpec; spec != nil {
+		spec.sort()
+	}
+	if spec := v.Cluster.GCPCloudSpec; spec != nil {
+		sort.SliceStable(spec.Subnets, func(i, j int) bool {
+			return spec.Subnets[i].Name < spec.Subnets[j].Name
+		})
+	}
+
+	if workers := v.Workers.AWSWorkers; workers != nil {
+		for _, worker := range *workers {
+			if worker != nil {
+				worker.Spec.sort()
+			}
+		}
+	}
+	if workers := v.Workers.AzureWorkers; workers != nil {
+		for _, worker := range *workers {
+			if worker != nil {
+				worker.Spec.sort()
+			}
+		}
+	}
+	if workers := v.Workers.GCPWorkers; workers != nil {
+		for _, worker := range *workers {
+			if worker == nil {
+				continue
+			}
+			if worker.Spec.KubernetesTaints != nil {
+				worker.Spec.KubernetesTaints.sort()
+			}
+			sort.Strings(worker.Spec.ProviderIDList)
+		}
+	}
+}
+
+func (spec *AWSCloudSpec) sort() {
+	sort.Strings(spec.EncryptionConfig.Resources)
+
+	roles := spec.IAMAuthenticatorConfig.RoleMappings
+	sort.SliceStable(roles, func(i, j int) bool {
+		return roles[i].RoleARN < roles[j].RoleARN
+	})
+	for i := range roles {
+		sort.Strings(roles[i].Groups)
+	}
+
+	users := spec.IAMAuthenticatorConfig.UserMappings
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].UserARN < users[j].UserARN
+	})
+	for i := range users {
+		sort.Strings(users[i].Groups)
+	}
+
+	sort.Strings(spec.EndpointAccess.PublicCIDRs)
+	sort.Strings(spec.RoleAdditionalPolicies)
+	sort.SliceStable(spec.Addons, func(i, j int) bool {
+		return spec.Addons[i].Name < spec.Addons[j].Name
+	})
+
+	subnets := spec.NetworkSpec.Subnets
+	sort.SliceStable(subnets, func(i, j int) bool {
+		if subnets[i].ID != subnets[j].ID {
+			return subnets[i].ID < subnets[j].ID
+		}
+		return subnets[i].CidrBlock < subnets[j].CidrBlock
+	})
+	if spec.NetworkSpec.CNI != nil {
+		rules := spec.NetworkSpec.CNI.CNIIngressRules
+		sort.SliceStable(rules, func(i, j int) bool {
+			if rules[i].Protocol != rules[j].Protocol {
+				return rules[i].Protocol < rules[j].Protocol
+			}
+			return rules[i].FromPort < rules[j].FromPort
+		})
+	}
+	sortIngressRules(spec.NetworkSpec.AdditionalControlPlaneIngressRules)
+	sort.Strings(spec.Bastion.AllowedCIDRBlocks)
+}
+
+func sortIngressRules(rules []infrav1.IngressRule) {
+	for i := range rules {
+		sort.Strings(rules[i].CidrBlocks)
+		sort.Strings(rules[i].SourceSecurityGroupIDs)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Description != rules[j].Description {
+			return rules[i].Description < rules[j].Description
+		}
+		if rules[i].Protocol != rules[j].Protocol {
+			return rules[i].Protocol < rules[j].Protocol
+		}
+		return rules[i].FromPort < rules[j].FromPort
+	})
+}
+
+func (spec *AWSWorkerSpec) sort() {
+	sort.Strings(spec.AvailabilityZones)
+	sort.SliceStable(spec.SubnetIDs, func(i, j int) bool {
+		return stringValue(spec.SubnetIDs[i]) < stringValue(spec.SubnetIDs[j])
+	})
+	spec.Taints.sort()
+	sort.Strings(spec.RoleAdditionalPolicies)
+}
+
+func (t Taints) sort() {
+	sort.SliceStable(t, func(i, j int) bool {
+		if t[i].Key != t[j].Key {
+			return t[i].Key < t[j].Key
+		}
+		return t[i].Effect < t[j].Effect
+	})
+}
+
+func (t GCPTaints) sort() {
+	sort.SliceStable(t, func(i, j int) bool {
+		if t[i].Key != t[j].Key {
+			return t[i].Key < t[j].Key
+		}
+		return t[i].Effect < t[j].Effect
+	})
+}
+
+func (spec *AzureCloudSpec) sort() {
+	sort.SliceStable(spec.AddonProfiles, func(i, j int) bool {
+		return spec.AddonProfiles[i].Name < spec.AddonProfiles[j].Name
+	})
+
+	subnet := &spec.VirtualNetwork.Subnet
+	endpoints := subnet.ServiceEndpoints
+	for i := range endpoints {
+		sort.Strings(endpoints[i].Locations)
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].Service < endpoints[j].Service
+	})
+
+	privateEndpoints := subnet.PrivateEndpoints
+	for i := range privateEndpoints {
+		endpoint := &privateEndpoints[i]
+		sort.Strings(endpoint.PrivateIPAddresses)
+		sort.Strings(endpoint.ApplicationSecurityGroups)
+		for j := range endpoint.PrivateLinkServiceConnections {
+			sort.Strings(endpoint.PrivateLinkServiceConnections[j].GroupIDs)
+		}
+		sort.SliceStable(endpoint.PrivateLinkServiceConnections, func(a, b int) bool {
+			return endpoint.PrivateLinkServiceConnections[a].Name < endpoint.PrivateLinkServiceConnections[b].Name
+		})
+	}
+	sort.SliceStable(privateEndpoints, func(i, j int) bool {
+		return privateEndpoints[i].Name < privateEndpoints[j].Name
+	})
+
+	if spec.AADProfile != nil {
+		sort.Strings(spec.AADProfile.AdminGroupObjectIDs)
+	}
+	if spec.LoadBalancerProfile != nil {
+		sort.Strings(spec.LoadBalancerProfile.OutboundIPPrefixes)
+		sort.Strings(spec.LoadBalancerProfile.OutboundIPs)
+	}
+	if spec.APIServerAccessProfile != nil && spec.APIServerAccessProfile.AuthorizedIPRanges != nil {
+		sort.Strings(*spec.APIServerAccessProfile.AuthorizedIPRanges)
+	}
+	if spec.AllowedNamespaces != nil {
+		sort.Strings(spec.AllowedNamespaces.NamespaceList)
+	}
+}
+
+func (spec *AzureWorkerSpec) sort() {
+	sort.Strings(spec.AvailabilityZones)
+	sort.SliceStable(spec.Taints, func(i, j int) bool {
+		if spec.Taints[i].Key != spec.Taints[j].Key {
+			return spec.Taints[i].Key < spec.Taints[j].Key
+		}
+		return spec.Taints[i].Effect < spec.Taints[j].Effect
+	})
+	if spec.KubeletConfig != nil {
+		sort.Strings(spec.KubeletConfig.AllowedUnsafeSysctls)
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
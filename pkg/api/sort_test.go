@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	"sigs.k8s.io/yaml"
+)
+
+// shuffle reorders the list in place with the random source, so that every build of the values gets different order.
+func shuffle[S ~[]E, E any](random *rand.Rand, list S) S {
+	random.Shuffle(len(list), func(i, j int) {
+		list[i], list[j] = list[j], list[i]
+	})
+
+	return list
+}
+
+func awsValues(random *rand.Rand) *Values {
+	spec := &AWSCloudSpec{
+		Addons: shuffle(random, []Addon{
+			{Name: "coredns", Version: "v1.8.7-eksbuild.3"},
+			{Name: "kube-proxy", Version: "v1.24.9-eksbuild.1"},
+			{Name: "vpc-cni", Version: "v1.12.2-eksbuild.1"},
+		}),
+		IAMAuthenticatorConfig: IAMAuthenticatorConfig{
+			RoleMappings: shuffle(random, []RoleMapping{
+				{RoleARN: "arn:aws:iam::123456789012:role/admin", KubernetesMapping: KubernetesMapping{UserName: "admin", Groups: shuffle(random, []string{"system:masters", "admins"})}},
+				{RoleARN: "arn:aws:iam::123456789012:role/nodes", KubernetesMapping: KubernetesMapping{UserName: "system:node:{{EC2PrivateDNSName}}", Groups: shuffle(random, []string{"system:nodes", "system:bootstrappers"})}},
+			}),
+			UserMappings: shuffle(random, []UserMapping{
+				{UserARN: "arn:aws:iam::123456789012:user/alice", KubernetesMapping: KubernetesMapping{UserName: "alice", Groups: []string{"developers"}}},
+				{UserARN: "arn:aws:iam::123456789012:user/bob", KubernetesMapping: KubernetesMapping{UserName: "bob", Groups: []string{"viewers"}}},
+			}),
+		},
+		NetworkSpec: infrav1.NetworkSpec{
+			Subnets: shuffle(random, []infrav1.SubnetSpec{
+				{ID: "subnet-1", CidrBlock: "10.0.0.0/19", AvailabilityZone: "eu-central-1a"},
+				{ID: "subnet-2", CidrBlock: "10.0.32.0/19", AvailabilityZone: "eu-central-1b"},
+				{ID: "subnet-3", CidrBlock: "10.0.64.0/19", AvailabilityZone: "eu-central-1c"},
+			}),
+		},
+	}
+
+	subnetIDs := []string{"subnet-1", "subnet-2", "subnet-3"}
+	worker := &AWSWorker{
+		Spec: AWSWorkerSpec{
+			AvailabilityZones: shuffle(random, []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"}),
+			SubnetIDs:         shuffle(random, []*string{&subnetIDs[0], &subnetIDs[1], &subnetIDs[2]}),
+			Taints: shuffle(random, Taints{
+				{Effect: TaintEffectNoSchedule, Key: "dedicated", Value: "gpu"},
+				{Effect: TaintEffectNoExecute, Key: "dedicated", Value: "gpu"},
+				{Effect: TaintEffectNoSchedule, Key: "spot", Value: "true"},
+			}),
+		},
+	}
+
+	return &Values{
+		APIVersion: Version,
+		Provider:   ClusterProviderAWS,
+		Cluster:    Cluster{Name: "test", CloudSpec: CloudSpec{AWSCloudSpec: spec}},
+		Workers:    Workers{WorkersSpec: WorkersSpec{AWSWorkers: &AWSWorkers{"workers": worker}}},
+	}
+}
+
+func azureValues(random *rand.Rand) *Values {
+	spec := &AzureCloudSpec{
+		AddonProfiles: shuffle(random, []AddonProfile{
+			{Name: "azurepolicy", Enabled: false},
+			{Name: "httpApplicationRouting", Enabled: false},
+			{Name: "omsagent", Enabled: true},
+		}),
+	}
+
+	worker := &AzureWorker{
+		Spec: AzureWorkerSpec{
+			AvailabilityZones: shuffle(random, []string{"1", "2", "3"}),
+			Taints: shuffle(random, []AzureTaint{
+				{Effect: "NoSchedule", Key: "dedicated", Value: "gpu"},
+				{Effect: "NoExecute", Key: "dedicated", Value: "gpu"},
+				{Effect: "NoSchedule", Key: "kubernetes.azure.com/scalesetpriority", Value: "spot"},
+			}),
+		},
+	}
+
+	return &Values{
+		APIVersion: Version,
+		Provider:   ClusterProviderAzure,
+		Cluster:    Cluster{Name: "test", CloudSpec: CloudSpec{AzureCloudSpec: spec}},
+		Workers:    Workers{WorkersSpec: WorkersSpec{AzureWorkers: &AzureWorkers{"workers": worker}}},
+	}
+}
+
+func gcpValues(random *rand.Rand) *Values {
+	spec := &GCPCloudSpec{
+		Subnets: shuffle(random, GCPSubnets{
+			{Name: "nodes", CidrBlock: "10.0.0.0/16"},
+			{Name: "pods", CidrBlock: "10.4.0.0/14"},
+			{Name: "services", CidrBlock: "10.8.0.0/20"},
+		}),
+	}
+
+	taints := shuffle(random, GCPTaints{
+		{Effect: "NoSchedule", Key: "dedicated", Value: "gpu"},
+		{Effect: "NoExecute", Key: "dedicated", Value: "gpu"},
+		{Effect: "NoSchedule", Key: "spot", Value: "true"},
+	})
+	worker := &GCPWorker{
+		Spec: GCPWorkerSpec{
+			KubernetesTaints: &taints,
+			ProviderIDList: shuffle(random, []string{
+				"gce://test/europe-west1-b/node-1",
+				"gce://test/europe-west1-c/node-2",
+				"gce://test/europe-west1-d/node-3",
+			}),
+		},
+	}
+
+	return &Values{
+		APIVersion: Version,
+		Provider:   ClusterProviderGCP,
+		Cluster:    Cluster{Name: "test", CloudSpec: CloudSpec{GCPCloudSpec: spec}},
+		Workers:    Workers{WorkersSpec: WorkersSpec{GCPWorkers: &GCPWorkers{"workers": worker}}},
+	}
+}
+
+func TestSortMakesValuesIndependentOfOrder(t *testing.T) {
+	for name, build := range map[string]func(*rand.Rand) *Values{
+		"aws":   awsValues,
+		"azure": azureValues,
+		"gcp":   gcpValues,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var expected []byte
+			for seed := int64(1); seed <= 5; seed++ {
+				values := build(rand.New(rand.NewSource(seed)))
+				values.Sort()
+
+				actual, err := yaml.Marshal(values)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if expected == nil {
+					expected = actual
+					continue
+				}
+				if !bytes.Equal(expected, actual) {
+					t.Fatalf("values built with seed %d differ after Sort:\n%s\nexpected:\n%s", seed, actual, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TagAction describes what happens with a tag when the plan is applied.
+type TagAction string
+
+const (
+	// TagActionAdd means that the resource does not have the tag yet.
+	TagActionAdd = TagAction("add")
+	// TagActionChange means that the resource has the tag with a different value.
+	TagActionChange = TagAction("change")
+	// TagActionNone means that the resource already has the tag with the same value.
+	TagActionNone = TagAction("none")
+)
+
+type TagChange struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// OldValue is the value the resource has before the plan is applied. Empty for added tags.
+	OldValue string    `json:"oldValue,omitempty"`
+	Action   TagAction `json:"action"`
+	// Applied is set once the tag was written to the resource, so that it can be reverted.
+	Applied bool `json:"applied,omitempty"`
+}
+
+func (c TagChange) String() string {
+	switch c.Action {
+	case TagActionAdd:
+		return fmt.Sprintf("+ %s=%s", c.Key, c.Value)
+	case TagActionChange:
+		return fmt.Sprintf("~ %s=%s (was %s)", c.Key, c.Value, c.OldValue)
+	}
+
+	return fmt.Sprintf("  %s=%s", c.Key, c.Value)
+}
+
+// ResourceTagPlan lists changes of the tags of a single cloud resource.
+type ResourceTagPlan struct {
+	// ID identifies the resource within the provider, i.e. VPC ID, ARN or Azure resource ID.
+	ID string `json:"id"`
+	// Kind of the resource, i.e. vpc, subnet or aks-cluster. Accessors use it to pick the API tags are written with.
+	Kind    string      `json:"kind"`
+	Changes []TagChange `json:"changes"`
+}
+
+// NewResourceTagPlan compares desired tags with the existing ones. Existing tags that are not desired are left out.
+func NewResourceTagPlan(kind, id string, existing, desired map[string]string) ResourceTagPlan {
+	plan := ResourceTagPlan{ID: id, Kind: kind, Changes: []TagChange{}}
+	for key, value := range desired {
+		change := TagChange{Key: key, Value: value, Action: TagActionAdd}
+		if old, ok := existing[key]; ok {
+			change.OldValue = old
+			change.Action = TagActionChange
+			if old == value {
+				change.Action = TagActionNone
+			}
+		}
+
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Key < plan.Changes[j].Key
+	})
+
+	return plan
+}
+
+// Tags returns tags that have to be written to the resource, i.e. added and changed ones that were not applied yet.
+func (p ResourceTagPlan) Tags() map[string]string {
+	result := map[string]string{}
+	for _, change := range p.Changes {
+		if change.Action != TagActionNone && !change.Applied {
+			result[change.Key] = change.Value
+		}
+	}
+
+	return result
+}
+
+func (p ResourceTagPlan) HasChanges() bool {
+	return len(p.Tags()) > 0
+}
+
+// Applied returns true if any tag was written to the resource.
+func (p ResourceTagPlan) Applied() bool {
+	for _, change := range p.Changes {
+		if change.Applied {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemovedKeys returns keys of the applied tags the resource did not have before, which have to be deleted on revert.
+func (p ResourceTagPlan) RemovedKeys() []string {
+	result := make([]string, 0)
+	for _, change := range p.Changes {
+		if change.Applied && change.Action == TagActionAdd {
+			result = append(result, change.Key)
+		}
+	}
+
+	return result
+}
+
+// RestoredTags returns previous values of the applied tags that were overwritten, which have to be written back on revert.
+func (p ResourceTagPlan) RestoredTags() map[string]string {
+	result := map[string]string{}
+	for _, change := range p.Changes {
+		if change.Applied && change.Action == TagActionChange {
+			result[change.Key] = change.OldValue
+		}
+	}
+
+	return result
+}
+
+func (p ResourceTagPlan) setApplied(applied bool) {
+	for i := range p.Changes {
+		if p.Changes[i].Action != TagActionNone {
+			p.Changes[i].Applied = applied
+		}
+	}
+}
+
+// SkippedResource is a cluster resource the provider cannot tag, listed in the plan so that it is not missed silently.
+type SkippedResource struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// TagJournal records progress of applying or reverting the tag plan, so that an interrupted run can be resumed.
+type TagJournal interface {
+	Record(plan *TagPlan) error
+}
+
+// TagPlan lists tag changes of all cluster resources. It is created by Migrator.PlanTags
+// and can be reviewed before it is applied with Migrator.ApplyTags.
+type TagPlan struct {
+	Provider ClusterProvider `json:"provider"`
+	// Tags the plan was created for.
+	Tags      map[string]string `json:"tags"`
+	Resources []ResourceTagPlan `json:"resources"`
+	// Skipped lists resources that Apply does not write tags to.
+	Skipped []SkippedResource `json:"skipped,omitempty"`
+
+	journal TagJournal
+}
+
+func NewTagPlan(provider ClusterProvider, tags map[string]string) *TagPlan {
+	return &TagPlan{Provider: provider, Tags: tags, Resources: []ResourceTagPlan{}}
+}
+
+// SetJournal makes Apply and Revert record the plan after every resource.
+func (p *TagPlan) SetJournal(journal TagJournal) {
+	p.journal = journal
+}
+
+func (p *TagPlan) record() error {
+	if p.journal == nil {
+		return nil
+	}
+
+	return p.journal.Record(p)
+}
+
+// Add compares desired tags of the resource with the existing ones and adds the result to the plan.
+func (p *TagPlan) Add(kind, id string, existing, desired map[string]string) {
+	p.Resources = append(p.Resources, NewResourceTagPlan(kind, id, existing, desired))
+}
+
+// Skip adds the resource to the plan without any changes, explaining why it cannot be tagged.
+func (p *TagPlan) Skip(kind, id, reason string) {
+	p.Skipped = append(p.Skipped, SkippedResource{ID: id, Kind: kind, Reason: reason})
+}
+
+// Apply calls write for every resource with pending changes and marks its changes as applied once write succeeds.
+// Resources applied before are skipped.
+func (p *TagPlan) Apply(write func(resource ResourceTagPlan) error) error {
+	for _, resource := range p.Resources {
+		if !resource.HasChanges() {
+			continue
+		}
+
+		if err := write(resource); err != nil {
+			return err
+		}
+
+		resource.setApplied(true)
+		if err := p.record(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Revert calls revert for every resource with applied changes in reverse order and marks its changes
+// as not applied once revert succeeds.
+func (p *TagPlan) Revert(revert func(resource ResourceTagPlan) error) error {
+	for i := len(p.Resources) - 1; i >= 0; i-- {
+		resource := p.Resources[i]
+		if !resource.Applied() {
+			continue
+		}
+
+		if err := revert(resource); err != nil {
+			return err
+		}
+
+		resource.setApplied(false)
+		if err := p.record(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Applied returns true if any tag from the plan was written.
+func (p *TagPlan) Applied() bool {
+	if p == nil {
+		return false
+	}
+
+	for _, resource := range p.Resources {
+		if resource.Applied() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasChanges returns true if applying the plan writes any tag.
+func (p *TagPlan) HasChanges() bool {
+	if p == nil {
+		return false
+	}
+
+	for _, resource := range p.Resources {
+		if resource.HasChanges() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StringTags converts tags returned by the cloud SDKs, skipping nil values.
+func StringTags(tags map[string]*string) map[string]string {
+	result := map[string]string{}
+	for key, value := range tags {
+		if value != nil {
+			result[key] = *value
+		}
+	}
+
+	return result
+}
+
+// PointerTags converts tags to the form used by the values, the reverse of StringTags.
+func PointerTags(tags map[string]string) map[string]*string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	result := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		value := value
+		result[key] = &value
+	}
+
+	return result
+}
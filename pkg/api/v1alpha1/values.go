@@ -0,0 +1,96 @@
+// Package v1alpha1 holds values written before they were versioned, which have no apiVersion field.
+//
+// Types that did not change since are shared with the current version in package api. Before a shared type
+// is changed in package api, its previous definition has to be copied here, so that this version keeps its schema,
+// and ConvertTo and ConvertFrom have to convert it.
+package v1alpha1
+
+import (
+	"strings"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+const Version = api.UnversionedVersion
+
+func init() {
+	api.RegisterVersion(Version, func() api.Convertible {
+		return &Values{}
+	})
+}
+
+type Values struct {
+	Provider api.ClusterProvider `json:"provider"`
+	Type     api.ClusterType     `json:"type"`
+	Cluster  api.Cluster         `json:"cluster"`
+	Workers  api.Workers         `json:"workers"`
+}
+
+// ConvertTo converts the values to the current version. Besides adding apiVersion, Kubernetes versions
+// get the v prefix, which this version had only in the cluster version of AWS.
+func (src *Values) ConvertTo(dst *api.Values) error {
+	*dst = api.Values{
+		APIVersion: api.Version,
+		Provider:   src.Provider,
+		Type:       src.Type,
+		Cluster:    src.Cluster,
+		Workers:    convertWorkerVersions(src.Workers, api.KubernetesVersion),
+	}
+	dst.Cluster.KubernetesVersion = api.KubernetesVersion(src.Cluster.KubernetesVersion)
+
+	return nil
+}
+
+// ConvertFrom converts values of the current version, leaving out apiVersion. Kubernetes versions lose
+// the v prefix, except for the cluster version of AWS.
+func (dst *Values) ConvertFrom(src *api.Values) error {
+	*dst = Values{
+		Provider: src.Provider,
+		Type:     src.Type,
+		Cluster:  src.Cluster,
+		Workers:  convertWorkerVersions(src.Workers, trimVersion),
+	}
+	if src.Provider != api.ClusterProviderAWS {
+		dst.Cluster.KubernetesVersion = trimVersion(src.Cluster.KubernetesVersion)
+	}
+
+	return nil
+}
+
+func trimVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
+
+// convertWorkerVersions returns copy of the workers with versions changed by convert, workers are copied
+// so that the values converted from keep their versions.
+func convertWorkerVersions(workers api.Workers, convert func(string) string) api.Workers {
+	if azureWorkers := workers.AzureWorkers; azureWorkers != nil {
+		result := make(api.AzureWorkers, len(*azureWorkers))
+		for name, worker := range *azureWorkers {
+			if worker != nil && worker.KubernetesVersion != nil {
+				copied := *worker
+				version := convert(*worker.KubernetesVersion)
+				copied.KubernetesVersion = &version
+				worker = &copied
+			}
+			result[name] = worker
+		}
+		workers.AzureWorkers = &result
+	}
+
+	if gcpWorkers := workers.GCPWorkers; gcpWorkers != nil {
+		result := make(api.GCPWorkers, len(*gcpWorkers))
+		for name, worker := range *gcpWorkers {
+			if worker != nil && worker.KubernetesVersion != nil {
+				copied := *worker
+				version := convert(*worker.KubernetesVersion)
+				copied.KubernetesVersion = &version
+				worker = &copied
+			}
+			result[name] = worker
+		}
+		workers.GCPWorkers = &result
+	}
+
+	return workers
+}
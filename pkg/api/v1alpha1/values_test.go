@@ -0,0 +1,272 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+func TestConversionPrefixesKubernetesVersions(t *testing.T) {
+	tests := []struct {
+		name            string
+		values          string
+		clusterVersion  string
+		workerVersion   string
+		restoredVersion string
+	}{
+		{
+			name: "aws",
+			values: `
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+`,
+			clusterVersion:  "v1.24",
+			restoredVersion: "v1.24",
+		},
+		{
+			name: "azure",
+			values: `
+provider: azure
+cluster:
+  name: test
+  kubernetesVersion: 1.24.9
+workers:
+  azure:
+    ssod:
+      kubernetesVersion: 1.24.6
+      spec:
+        mode: System
+        sku: Standard_D2s_v5
+`,
+			clusterVersion:  "v1.24.9",
+			workerVersion:   "v1.24.6",
+			restoredVersion: "1.24.9",
+		},
+		{
+			name: "gcp",
+			values: `
+provider: gcp
+cluster:
+  name: test
+  kubernetesVersion: 1.25.7-gke.1000
+workers:
+  gcp:
+    small-burst-on-demand:
+      kubernetesVersion: 1.25.6-gke.200
+      spec: {}
+`,
+			clusterVersion:  "v1.25.7-gke.1000",
+			workerVersion:   "v1.25.6-gke.200",
+			restoredVersion: "1.25.7-gke.1000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, version, err := api.DecodeValues([]byte(test.values))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != Version {
+				t.Errorf("values were read as %s, expected %s", version, Version)
+			}
+			if values.Cluster.KubernetesVersion != test.clusterVersion {
+				t.Errorf("cluster version = %q, expected %q", values.Cluster.KubernetesVersion, test.clusterVersion)
+			}
+			if len(test.workerVersion) > 0 {
+				if actual := workerVersion(values); actual != test.workerVersion {
+					t.Errorf("worker version = %q, expected %q", actual, test.workerVersion)
+				}
+			}
+
+			converted, err := api.ConvertValues(values, Version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored := converted.(*Values)
+			if restored.Cluster.KubernetesVersion != test.restoredVersion {
+				t.Errorf("cluster version converted back = %q, expected %q", restored.Cluster.KubernetesVersion, test.restoredVersion)
+			}
+			if actual := workerVersion(values); actual != test.workerVersion {
+				t.Errorf("converting back changed worker version of the current values to %q", actual)
+			}
+		})
+	}
+}
+
+// workerVersion returns version of the only worker of the values, or empty string if there is none.
+func workerVersion(values *api.Values) string {
+	if workers := values.Workers.AzureWorkers; workers != nil {
+		for _, worker := range *workers {
+			return *worker.KubernetesVersion
+		}
+	}
+	if workers := values.Workers.GCPWorkers; workers != nil {
+		for _, worker := range *workers {
+			return *worker.KubernetesVersion
+		}
+	}
+
+	return ""
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+	}{
+		{
+			name: "aws",
+			values: `
+provider: aws
+type: managed
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+workers:
+  aws:
+    small-burst-on-demand:
+      replicas: 1
+      spec:
+        instanceType: t3.large
+`,
+		},
+		{
+			name: "azure",
+			values: `
+provider: azure
+type: managed
+cluster:
+  name: test
+  kubernetesVersion: 1.24.9
+workers:
+  azure:
+    ssod:
+      replicas: 3
+      kubernetesVersion: 1.24.6
+      spec:
+        mode: System
+        sku: Standard_D2s_v5
+`,
+		},
+		{
+			name: "gcp",
+			values: `
+provider: gcp
+type: managed
+cluster:
+  name: test
+  kubernetesVersion: 1.25.7-gke.1000
+workers:
+  gcp:
+    small-burst-on-demand:
+      replicas: 1
+      kubernetesVersion: 1.25.6-gke.200
+      spec: {}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := &Values{}
+			if err := yaml.UnmarshalStrict([]byte(test.values), expected); err != nil {
+				t.Fatal(err)
+			}
+
+			values, _, err := api.DecodeValues([]byte(test.values))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if values.APIVersion != api.Version {
+				t.Errorf("apiVersion = %q, expected %q", values.APIVersion, api.Version)
+			}
+
+			converted, err := api.ConvertValues(values, Version)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(converted, expected) {
+				actual, _ := yaml.Marshal(converted)
+				t.Errorf("values converted back differ from the input:\n%s", actual)
+			}
+		})
+	}
+}
+
+func TestDecodeValuesDetectsVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   string
+		expected string
+		err      bool
+	}{
+		{
+			name: "missing apiVersion is read as this version",
+			values: `
+provider: aws
+cluster:
+  name: test
+`,
+			expected: Version,
+		},
+		{
+			name: "current version",
+			values: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+`,
+			expected: api.Version,
+		},
+		{
+			name: "unknown version",
+			values: `
+apiVersion: v2
+provider: aws
+cluster:
+  name: test
+`,
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, version, err := api.DecodeValues([]byte(test.values))
+			if test.err {
+				if err == nil {
+					t.Errorf("values were read as %s, expected an error", version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != test.expected {
+				t.Errorf("values were read as %s, expected %s", version, test.expected)
+			}
+		})
+	}
+}
+
+func TestConvertValuesFailsForUnknownVersion(t *testing.T) {
+	values := &api.Values{APIVersion: api.Version, Provider: api.ClusterProviderAWS}
+	if _, err := api.ConvertValues(values, "v2"); err == nil {
+		t.Error("values were converted to unknown version v2, expected an error")
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validator is implemented by every provider configuration. Validate must not connect to the cloud provider.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes a single invalid configuration field.
+type FieldError struct {
+	// Field is the JSON name of the invalid field, i.e. region.
+	Field string
+	// Message describes why the value is invalid.
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// FieldErrors aggregates all invalid fields of a configuration. Single field errors can be checked with errors.As.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(messages, ", "))
+}
+
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// Add records invalid field.
+func (e *FieldErrors) Add(field, format string, args ...interface{}) {
+	*e = append(*e, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ErrorOrNil returns nil if there are no field errors, so that it can be returned directly from Validate.
+func (e FieldErrors) ErrorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
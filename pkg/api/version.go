@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Version is the apiVersion of values written by this release. Values of older versions are converted to it when read.
+	Version = "v1alpha2"
+	// UnversionedVersion is assumed for values without apiVersion, which were written before values were versioned.
+	UnversionedVersion = "v1alpha1"
+)
+
+// Convertible is implemented by values of older versions. Values is the hub all versions convert through,
+// so older versions only convert to and from the current one.
+type Convertible interface {
+	// ConvertTo converts the values to the current version.
+	ConvertTo(dst *Values) error
+	// ConvertFrom converts values of the current version, fields the older version does not have are lost.
+	ConvertFrom(src *Values) error
+}
+
+var (
+	versionsMu sync.RWMutex
+	versions   = map[string]func() Convertible{}
+)
+
+// RegisterVersion makes values of an older version readable. Packages of the versions register themselves in init.
+// It panics if the version is the current one or if it was already registered.
+func RegisterVersion(version string, newValues func() Convertible) {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+
+	if version == Version {
+		panic(fmt.Sprintf("version %q is the current version", version))
+	}
+
+	if _, ok := versions[version]; ok {
+		panic(fmt.Sprintf("version %q is already registered", version))
+	}
+
+	versions[version] = newValues
+}
+
+// Versions returns sorted versions of values that can be read, including the current one.
+func Versions() []string {
+	versionsMu.RLock()
+	defer versionsMu.RUnlock()
+
+	result := []string{Version}
+	for version := range versions {
+		result = append(result, version)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// DecodeValues reads values of any registered version in YAML or JSON and converts them to the current version.
+// The version the values were written in is returned too. Top-level keys that are not part of the values,
+// i.e. other settings of the chart, are ignored. Cluster and workers are always decoded strictly.
+func DecodeValues(data []byte) (*Values, string, error) {
+	return decodeValues(data, func(data []byte, target interface{}) error {
+		return yaml.Unmarshal(data, target)
+	})
+}
+
+// DecodeValuesStrict is DecodeValues that fails on keys that are not part of the values, so that values can be
+// rewritten without losing anything.
+func DecodeValuesStrict(data []byte) (*Values, string, error) {
+	return decodeValues(data, func(data []byte, target interface{}) error {
+		return yaml.UnmarshalStrict(data, target)
+	})
+}
+
+func decodeValues(data []byte, unmarshal func(data []byte, target interface{}) error) (*Values, string, error) {
+	header := struct {
+		APIVersion string `json:"apiVersion"`
+	}{}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, "", err
+	}
+
+	version := header.APIVersion
+	if len(version) == 0 {
+		version = UnversionedVersion
+	}
+
+	values := &Values{}
+	if version == Version {
+		if err := unmarshal(data, values); err != nil {
+			return nil, "", err
+		}
+
+		return values, version, nil
+	}
+
+	old, err := newVersion(version)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = unmarshal(data, old); err != nil {
+		return nil, "", err
+	}
+
+	if err = old.ConvertTo(values); err != nil {
+		return nil, "", fmt.Errorf("cannot convert values from %s to %s: %w", version, Version, err)
+	}
+
+	return values, version, nil
+}
+
+// ConvertValues returns values in the given version, i.e. for charts that do not accept the current one yet.
+func ConvertValues(values *Values, version string) (interface{}, error) {
+	if version == Version {
+		return values, nil
+	}
+
+	result, err := newVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = result.ConvertFrom(values); err != nil {
+		return nil, fmt.Errorf("cannot convert values from %s to %s: %w", Version, version, err)
+	}
+
+	return result, nil
+}
+
+func newVersion(version string) (Convertible, error) {
+	versionsMu.RLock()
+	newValues, ok := versions[version]
+	versionsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported values apiVersion %q, supported versions are %v", version, Versions())
+	}
+
+	return newValues(), nil
+}
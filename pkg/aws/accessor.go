@@ -2,75 +2,122 @@ package aws
 
 import (
 	"context"
-	"time"
+	"encoding/json"
+	"fmt"
 
-	"github.com/aws/amazon-ec2-instance-selector/v2/pkg/selector"
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 	"github.com/pluralsh/cluster-api-migration/pkg/aws/cluster"
 	"github.com/pluralsh/cluster-api-migration/pkg/aws/worker"
-	"github.com/weaveworks/eksctl/pkg/actions/addon"
-	"github.com/weaveworks/eksctl/pkg/actions/nodegroup"
-	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
 )
 
 type ClusterAccessor struct {
 	configuration *api.AWSConfiguration
 	cluster       *cluster.Cluster
 	worker        *worker.Worker
+	// snapshot holds responses read by GetCluster and GetWorkers, or responses to replay.
+	snapshot *Snapshot
+	replay   bool
 }
 
-func (this *ClusterAccessor) AddClusterTags(tags map[string]string) error {
-	return this.cluster.AddClusterTags(tags)
+// Snapshot holds raw responses the cluster and workers are converted from.
+type Snapshot struct {
+	Cluster *cluster.Snapshot `json:"cluster"`
+	Workers *worker.Snapshot  `json:"workers"`
 }
 
-func (this *ClusterAccessor) AddMachinePollsTags(tags map[string]string) error {
-	return this.worker.AddMachinePollsTags(tags)
+func (this *ClusterAccessor) PlanClusterTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	if this.replay {
+		return api.ErrReplay
+	}
+	return wrapError(this.resource(), this.cluster.PlanClusterTags(ctx, tags, plan))
+}
+
+func (this *ClusterAccessor) PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	if this.replay {
+		return api.ErrReplay
+	}
+	return wrapError(this.resource(), this.worker.PlanMachinePoolsTags(ctx, tags, plan))
 }
 
-func (this *ClusterAccessor) AddVirtualNetworkTags(tags map[string]string) error {
+func (this *ClusterAccessor) PlanVirtualNetworkTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (this *ClusterAccessor) GetCluster() (*api.Cluster, error) {
-	return this.cluster.GetCluster()
+func (this *ClusterAccessor) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	if this.replay {
+		return api.ErrReplay
+	}
+	return wrapError(this.resource(), this.cluster.ApplyTags(ctx, plan))
 }
 
-func (this *ClusterAccessor) GetWorkers() (*api.Workers, error) {
-	return this.worker.GetWorkers()
+func (this *ClusterAccessor) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	if this.replay {
+		return api.ErrReplay
+	}
+	return wrapError(this.resource(), this.cluster.RemoveTags(ctx, plan))
 }
 
-func (this *ClusterAccessor) init() (api.ClusterAccessor, error) {
-	ctx := context.Background()
-	cmd := &cmdutils.Cmd{}
-	cfg := getCfg()
-	cmd.ClusterConfig = cfg
-	cmd.ClusterConfig.Metadata.Name = this.configuration.ClusterName
-	cmd.ClusterConfig.Metadata.Region = this.configuration.Region
-	cmd.ProviderConfig.WaitTimeout = time.Minute * 5
-	clusterProvider, err := cmd.NewProviderForExistingCluster(ctx)
-	if err != nil {
-		return nil, err
+func (this *ClusterAccessor) GetCluster(ctx context.Context, report *api.ConversionReport) (*api.Cluster, error) {
+	if !this.replay {
+		s, err := this.cluster.Fetch(ctx)
+		if err != nil {
+			return nil, wrapError(this.resource(), err)
+		}
+		this.snapshot.Cluster = s
 	}
 
-	if ok, err := clusterProvider.CanOperate(cfg); !ok {
-		return nil, err
+	if this.snapshot.Cluster == nil {
+		return nil, fmt.Errorf("%s: cluster responses were not recorded", this.resource())
 	}
-	clientSet, err := clusterProvider.NewStdClientSet(cfg)
-	if err != nil {
-		return nil, err
+
+	return this.cluster.Convert(this.snapshot.Cluster, report)
+}
+
+func (this *ClusterAccessor) GetWorkers(ctx context.Context, report *api.ConversionReport) (*api.Workers, error) {
+	if !this.replay {
+		s, err := this.worker.Fetch(ctx)
+		if err != nil {
+			return nil, wrapError(this.resource(), err)
+		}
+		this.snapshot.Workers = s
 	}
-	instanceSelector, err := selector.New(ctx, clusterProvider.AWSProvider.AWSConfig())
-	if err != nil {
-		return nil, err
+
+	if this.snapshot.Workers == nil {
+		return nil, fmt.Errorf("%s: workers responses were not recorded", this.resource())
 	}
-	nodeGroupProvider := nodegroup.New(cfg, clusterProvider, clientSet, instanceSelector)
-	stackManager := clusterProvider.NewStackManager(cmd.ClusterConfig)
-	addonProvider, err := addon.New(cmd.ClusterConfig, clusterProvider.AWSProvider.EKS(), stackManager, *cmd.ClusterConfig.IAM.WithOIDC, nil, nil)
+
+	return this.worker.Convert(this.snapshot.Workers, report)
+}
+
+func (this *ClusterAccessor) Fixture() (*api.Fixture, error) {
+	return api.NewFixture(api.ClusterProviderAWS, this.configuration, this.snapshot)
+}
+
+func (this *ClusterAccessor) resource() string {
+	return fmt.Sprintf("EKS cluster %s in region %s", this.configuration.ClusterName, this.configuration.Region)
+}
+
+func (this *ClusterAccessor) init(ctx context.Context) (api.ClusterAccessor, error) {
+	ec2Client, eksClient, err := newClients(ctx, this.configuration)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(this.resource(), err)
+	}
+
+	this.cluster = cluster.NewAWSCluster(this.configuration, ec2Client, eksClient)
+	this.worker = worker.NewAWSWorker(this.configuration, ec2Client, eksClient)
+	this.snapshot = &Snapshot{}
+	return this, nil
+}
+
+// initReplay makes the accessor convert recorded responses without connecting to AWS.
+func (this *ClusterAccessor) initReplay(responses []byte) (api.ClusterAccessor, error) {
+	this.snapshot = &Snapshot{}
+	if err := json.Unmarshal(responses, this.snapshot); err != nil {
+		return nil, fmt.Errorf("invalid AWS responses: %w", err)
 	}
 
-	this.cluster = cluster.NewAWSCluster(ctx, this.configuration, clusterProvider, nodeGroupProvider, addonProvider)
-	this.worker = worker.NewAWSWorker(ctx, this.configuration, clusterProvider)
+	this.cluster = cluster.NewAWSCluster(this.configuration, nil, nil)
+	this.worker = worker.NewAWSWorker(this.configuration, nil, nil)
+	this.replay = true
 	return this, nil
 }
@@ -0,0 +1,632 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/smithy-go"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/aws/cluster"
+	"github.com/pluralsh/cluster-api-migration/pkg/aws/worker"
+	"github.com/pluralsh/cluster-api-migration/pkg/journal"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// fakeEC2 answers EC2 requests from its fields. Operations listed in errors fail with the given error.
+type fakeEC2 struct {
+	availabilityZones []ec2Types.AvailabilityZone
+	vpc               ec2Types.Vpc
+	endpoints         []ec2Types.VpcEndpoint
+	subnets           []ec2Types.Subnet
+	// routeTables and natGateways by subnet ID.
+	routeTables    map[string][]ec2Types.RouteTable
+	natGateways    map[string][]ec2Types.NatGateway
+	securityGroups []ec2Types.SecurityGroup
+	errors         map[string]error
+	// tags by resource ID, written by CreateTags and DeleteTags.
+	tags map[string]map[string]string
+	// tagErrors make CreateTags fail for the resource ID, so that a tagging run can be stopped in the middle.
+	tagErrors map[string]error
+}
+
+func (f *fakeEC2) DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return &ec2.DescribeAvailabilityZonesOutput{AvailabilityZones: f.availabilityZones}, f.errors["DescribeAvailabilityZones"]
+}
+
+func (f *fakeEC2) DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{Vpcs: []ec2Types.Vpc{f.vpc}}, f.errors["DescribeVpcs"]
+}
+
+func (f *fakeEC2) DescribeVpcEndpoints(ctx context.Context, params *ec2.DescribeVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error) {
+	return &ec2.DescribeVpcEndpointsOutput{VpcEndpoints: f.endpoints}, f.errors["DescribeVpcEndpoints"]
+}
+
+func (f *fakeEC2) DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	ids := filterValues(params.Filters, "subnet-id")
+	if ids == nil {
+		return &ec2.DescribeSubnetsOutput{Subnets: f.subnets}, f.errors["DescribeSubnets"]
+	}
+
+	subnets := []ec2Types.Subnet{}
+	for _, subnet := range f.subnets {
+		for _, id := range ids {
+			if *subnet.SubnetId == id {
+				subnets = append(subnets, subnet)
+			}
+		}
+	}
+	return &ec2.DescribeSubnetsOutput{Subnets: subnets}, f.errors["DescribeSubnets"]
+}
+
+func (f *fakeEC2) DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	routeTables := []ec2Types.RouteTable{}
+	for _, id := range filterValues(params.Filters, "association.subnet-id") {
+		routeTables = append(routeTables, f.routeTables[id]...)
+	}
+	return &ec2.DescribeRouteTablesOutput{RouteTables: routeTables}, f.errors["DescribeRouteTables"]
+}
+
+func (f *fakeEC2) DescribeNatGateways(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+	natGateways := []ec2Types.NatGateway{}
+	for _, id := range filterValues(params.Filter, "subnet-id") {
+		natGateways = append(natGateways, f.natGateways[id]...)
+	}
+	return &ec2.DescribeNatGatewaysOutput{NatGateways: natGateways}, f.errors["DescribeNatGateways"]
+}
+
+func (f *fakeEC2) DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: f.securityGroups}, f.errors["DescribeSecurityGroups"]
+}
+
+func (f *fakeEC2) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	if err := f.errors["CreateTags"]; err != nil {
+		return nil, err
+	}
+	for _, id := range params.Resources {
+		if err := f.tagErrors[id]; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range params.Resources {
+		for _, tag := range params.Tags {
+			setTag(f.tags, id, *tag.Key, *tag.Value)
+		}
+	}
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (f *fakeEC2) DeleteTags(ctx context.Context, params *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	if err := f.errors["DeleteTags"]; err != nil {
+		return nil, err
+	}
+
+	for _, id := range params.Resources {
+		for _, tag := range params.Tags {
+			deleteTag(f.tags, id, *tag.Key)
+		}
+	}
+	return &ec2.DeleteTagsOutput{}, nil
+}
+
+func setTag(tags map[string]map[string]string, id, key, value string) {
+	if tags[id] == nil {
+		tags[id] = map[string]string{}
+	}
+	tags[id][key] = value
+}
+
+// deleteTag removes the tag and resources that are left without tags, so that tags can be compared with the original ones.
+func deleteTag(tags map[string]map[string]string, id, key string) {
+	delete(tags[id], key)
+	if len(tags[id]) == 0 {
+		delete(tags, id)
+	}
+}
+
+func filterValues(filters []ec2Types.Filter, name string) []string {
+	for _, filter := range filters {
+		if filter.Name != nil && *filter.Name == name {
+			return filter.Values
+		}
+	}
+
+	return nil
+}
+
+// fakeEKS answers EKS requests from its fields. Operations listed in errors fail with the given error.
+type fakeEKS struct {
+	cluster    ekstypes.Cluster
+	addons     []ekstypes.Addon
+	nodegroups []ekstypes.Nodegroup
+	errors     map[string]error
+	// tags by ARN, written by TagResource and UntagResource.
+	tags map[string]map[string]string
+}
+
+func (f *fakeEKS) DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+	return &eks.DescribeClusterOutput{Cluster: &f.cluster}, f.errors["DescribeCluster"]
+}
+
+func (f *fakeEKS) ListAddons(ctx context.Context, params *eks.ListAddonsInput, optFns ...func(*eks.Options)) (*eks.ListAddonsOutput, error) {
+	output := &eks.ListAddonsOutput{}
+	for _, addon := range f.addons {
+		output.Addons = append(output.Addons, *addon.AddonName)
+	}
+	return output, f.errors["ListAddons"]
+}
+
+func (f *fakeEKS) DescribeAddon(ctx context.Context, params *eks.DescribeAddonInput, optFns ...func(*eks.Options)) (*eks.DescribeAddonOutput, error) {
+	for _, addon := range f.addons {
+		if *addon.AddonName == *params.AddonName {
+			return &eks.DescribeAddonOutput{Addon: &addon}, f.errors["DescribeAddon"]
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: "addon not found"}
+}
+
+func (f *fakeEKS) ListNodegroups(ctx context.Context, params *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error) {
+	output := &eks.ListNodegroupsOutput{}
+	for _, nodegroup := range f.nodegroups {
+		output.Nodegroups = append(output.Nodegroups, *nodegroup.NodegroupName)
+	}
+	return output, f.errors["ListNodegroups"]
+}
+
+func (f *fakeEKS) DescribeNodegroup(ctx context.Context, params *eks.DescribeNodegroupInput, optFns ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+	for _, nodegroup := range f.nodegroups {
+		if *nodegroup.NodegroupName == *params.NodegroupName {
+			return &eks.DescribeNodegroupOutput{Nodegroup: &nodegroup}, f.errors["DescribeNodegroup"]
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: "node group not found"}
+}
+
+func (f *fakeEKS) TagResource(ctx context.Context, params *eks.TagResourceInput, optFns ...func(*eks.Options)) (*eks.TagResourceOutput, error) {
+	if err := f.errors["TagResource"]; err != nil {
+		return nil, err
+	}
+
+	for key, value := range params.Tags {
+		setTag(f.tags, *params.ResourceArn, key, value)
+	}
+	return &eks.TagResourceOutput{}, nil
+}
+
+func (f *fakeEKS) UntagResource(ctx context.Context, params *eks.UntagResourceInput, optFns ...func(*eks.Options)) (*eks.UntagResourceOutput, error) {
+	if err := f.errors["UntagResource"]; err != nil {
+		return nil, err
+	}
+
+	for _, key := range params.TagKeys {
+		deleteTag(f.tags, *params.ResourceArn, key)
+	}
+	return &eks.UntagResourceOutput{}, nil
+}
+
+func newFakeClients() (*fakeEC2, *fakeEKS) {
+	ec2Client := &fakeEC2{
+		availabilityZones: []ec2Types.AvailabilityZone{
+			{ZoneName: ptr("eu-central-1a")},
+			{ZoneName: ptr("eu-central-1b")},
+		},
+		vpc: ec2Types.Vpc{
+			VpcId:     ptr("vpc-1"),
+			CidrBlock: ptr("10.0.0.0/16"),
+			Tags:      []ec2Types.Tag{{Key: ptr("Name"), Value: ptr("test")}},
+		},
+		endpoints: []ec2Types.VpcEndpoint{{VpcEndpointId: ptr("vpce-1")}},
+		subnets: []ec2Types.Subnet{
+			{
+				SubnetId:         ptr("subnet-1"),
+				CidrBlock:        ptr("10.0.0.0/19"),
+				AvailabilityZone: ptr("eu-central-1a"),
+				Tags:             []ec2Types.Tag{{Key: ptr("kubernetes.io/role/internal-elb"), Value: ptr("1")}},
+			},
+			{
+				SubnetId:         ptr("subnet-2"),
+				CidrBlock:        ptr("10.0.32.0/19"),
+				AvailabilityZone: ptr("eu-central-1b"),
+			},
+		},
+		routeTables: map[string][]ec2Types.RouteTable{
+			"subnet-1": {{RouteTableId: ptr("rtb-1")}},
+			"subnet-2": {{RouteTableId: ptr("rtb-2")}},
+		},
+		natGateways: map[string][]ec2Types.NatGateway{
+			"subnet-1": {{NatGatewayId: ptr("nat-1")}},
+		},
+		securityGroups: []ec2Types.SecurityGroup{
+			{GroupId: ptr("sg-1"), GroupName: ptr("default")},
+			{GroupId: ptr("sg-2"), GroupName: ptr("eks-cluster-sg-test")},
+		},
+		tags: map[string]map[string]string{
+			"vpc-1":    {"Name": "test"},
+			"subnet-1": {"kubernetes.io/role/internal-elb": "1"},
+		},
+	}
+	eksClient := &fakeEKS{
+		cluster: ekstypes.Cluster{
+			Arn:      ptr("arn:aws:eks:eu-central-1:123456789012:cluster/test"),
+			Name:     ptr("test"),
+			Version:  ptr("1.24"),
+			Endpoint: ptr("https://test.eks.amazonaws.com"),
+			RoleArn:  ptr("arn:aws:iam::123456789012:role/test-cluster"),
+			ResourcesVpcConfig: &ekstypes.VpcConfigResponse{
+				VpcId:                ptr("vpc-1"),
+				EndpointPublicAccess: true,
+				PublicAccessCidrs:    []string{"0.0.0.0/0"},
+			},
+			Tags: map[string]string{"team": "plural"},
+		},
+		addons: []ekstypes.Addon{
+			{AddonName: ptr("coredns"), AddonVersion: ptr("v1.8.7-eksbuild.3")},
+			{AddonName: ptr("vpc-cni"), AddonVersion: ptr("v1.12.2-eksbuild.1")},
+		},
+		nodegroups: []ekstypes.Nodegroup{
+			{
+				NodegroupName: ptr("small"),
+				NodegroupArn:  ptr("arn:aws:eks:eu-central-1:123456789012:nodegroup/test/small/1"),
+				Subnets:       []string{"subnet-1", "subnet-2"},
+				InstanceTypes: []string{"t3.large"},
+				DiskSize:      ptr(int32(50)),
+				ScalingConfig: &ekstypes.NodegroupScalingConfig{
+					DesiredSize: ptr(int32(2)),
+					MinSize:     ptr(int32(1)),
+					MaxSize:     ptr(int32(3)),
+				},
+			},
+		},
+		tags: map[string]map[string]string{
+			"arn:aws:eks:eu-central-1:123456789012:cluster/test": {"team": "plural"},
+		},
+	}
+
+	return ec2Client, eksClient
+}
+
+func newFakeAccessor(ec2Client *fakeEC2, eksClient *fakeEKS) *ClusterAccessor {
+	configuration := &api.AWSConfiguration{ClusterName: "test", Region: "eu-central-1"}
+	return &ClusterAccessor{
+		configuration: configuration,
+		cluster:       cluster.NewAWSCluster(configuration, ec2Client, eksClient),
+		worker:        worker.NewAWSWorker(configuration, ec2Client, eksClient),
+		snapshot:      &Snapshot{},
+	}
+}
+
+func errorClassOf(err error) api.ErrorClass {
+	var notFound *api.NotFoundError
+	var permissionDenied *api.PermissionDeniedError
+	var throttled *api.ThrottledError
+	switch {
+	case errors.As(err, &notFound):
+		return api.ErrorClassNotFound
+	case errors.As(err, &permissionDenied):
+		return api.ErrorClassPermissionDenied
+	case errors.As(err, &throttled):
+		return api.ErrorClassThrottled
+	}
+
+	return api.ErrorClassUnknown
+}
+
+func TestAccessorMapsErrors(t *testing.T) {
+	getCluster := func(accessor *ClusterAccessor) error {
+		_, err := accessor.GetCluster(context.Background(), api.NewConversionReport())
+		return err
+	}
+	getWorkers := func(accessor *ClusterAccessor) error {
+		_, err := accessor.GetWorkers(context.Background(), api.NewConversionReport())
+		return err
+	}
+	planClusterTags := func(accessor *ClusterAccessor) error {
+		return accessor.PlanClusterTags(context.Background(), map[string]string{"team": "plural"}, api.NewTagPlan(api.ClusterProviderAWS, nil))
+	}
+
+	tests := []struct {
+		name      string
+		call      func(accessor *ClusterAccessor) error
+		ec2Errors map[string]error
+		eksErrors map[string]error
+		expected  api.ErrorClass
+	}{
+		{
+			name:      "missing cluster",
+			call:      getCluster,
+			eksErrors: map[string]error{"DescribeCluster": &smithy.GenericAPIError{Code: "ResourceNotFoundException"}},
+			expected:  api.ErrorClassNotFound,
+		},
+		{
+			name:      "missing vpc",
+			call:      getCluster,
+			ec2Errors: map[string]error{"DescribeVpcs": &smithy.GenericAPIError{Code: "InvalidVpcID.NotFound"}},
+			expected:  api.ErrorClassNotFound,
+		},
+		{
+			name:      "denied subnets",
+			call:      getCluster,
+			ec2Errors: map[string]error{"DescribeSubnets": &smithy.GenericAPIError{Code: "UnauthorizedOperation"}},
+			expected:  api.ErrorClassPermissionDenied,
+		},
+		{
+			name:      "expired credentials",
+			call:      getCluster,
+			eksErrors: map[string]error{"DescribeCluster": awserr.New("ExpiredToken", "token expired", nil)},
+			expected:  api.ErrorClassPermissionDenied,
+		},
+		{
+			name:      "throttled addons",
+			call:      getCluster,
+			eksErrors: map[string]error{"ListAddons": &smithy.GenericAPIError{Code: "ThrottlingException"}},
+			expected:  api.ErrorClassThrottled,
+		},
+		{
+			name:      "throttled node groups",
+			call:      getWorkers,
+			eksErrors: map[string]error{"ListNodegroups": &smithy.GenericAPIError{Code: "TooManyRequestsException"}},
+			expected:  api.ErrorClassThrottled,
+		},
+		{
+			name:      "throttled tag planning",
+			call:      planClusterTags,
+			ec2Errors: map[string]error{"DescribeRouteTables": &smithy.GenericAPIError{Code: "RequestLimitExceeded"}},
+			expected:  api.ErrorClassThrottled,
+		},
+		{
+			name:      "unknown error",
+			call:      getCluster,
+			eksErrors: map[string]error{"DescribeCluster": &smithy.GenericAPIError{Code: "InternalFailure"}},
+			expected:  api.ErrorClassUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ec2Client, eksClient := newFakeClients()
+			ec2Client.errors = test.ec2Errors
+			eksClient.errors = test.eksErrors
+
+			err := test.call(newFakeAccessor(ec2Client, eksClient))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if class := errorClassOf(err); class != test.expected {
+				t.Errorf("error %q has class %d, expected %d", err, class, test.expected)
+			}
+
+			var apiErr smithy.APIError
+			var awsErr awserr.Error
+			if !errors.As(err, &apiErr) && !errors.As(err, &awsErr) {
+				t.Errorf("error %q does not wrap the AWS error", err)
+			}
+		})
+	}
+}
+
+func TestGetClusterConvertsFetchedResponses(t *testing.T) {
+	accessor := newFakeAccessor(newFakeClients())
+
+	values, err := accessor.GetCluster(context.Background(), api.NewConversionReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values.Name != "test" || values.KubernetesVersion != "v1.24" {
+		t.Errorf("cluster %s has version %s, expected test with v1.24", values.Name, values.KubernetesVersion)
+	}
+
+	spec := values.AWSCloudSpec
+	if spec.RoleName != "test-cluster" {
+		t.Errorf("role name = %q, expected test-cluster", spec.RoleName)
+	}
+	if len(spec.Addons) != 2 || spec.Addons[0].Name != "coredns" || spec.Addons[1].Version != "v1.12.2-eksbuild.1" {
+		t.Errorf("addons = %+v, expected coredns and vpc-cni", spec.Addons)
+	}
+	if spec.NetworkSpec.VPC.ID != "vpc-1" || *spec.NetworkSpec.VPC.AvailabilityZoneUsageLimit != 2 {
+		t.Errorf("vpc = %+v, expected vpc-1 limited to 2 availability zones", spec.NetworkSpec.VPC)
+	}
+
+	subnets := spec.NetworkSpec.Subnets
+	if len(subnets) != 2 {
+		t.Fatalf("subnets = %+v, expected subnet-1 and subnet-2", subnets)
+	}
+	if *subnets[0].RouteTableID != "rtb-1" || *subnets[0].NatGatewayID != "nat-1" {
+		t.Errorf("subnet-1 has route table %v and NAT gateway %v, expected rtb-1 and nat-1", subnets[0].RouteTableID, subnets[0].NatGatewayID)
+	}
+	if *subnets[1].RouteTableID != "rtb-2" || subnets[1].NatGatewayID != nil {
+		t.Errorf("subnet-2 has route table %v and NAT gateway %v, expected rtb-2 and none", subnets[1].RouteTableID, subnets[1].NatGatewayID)
+	}
+
+	if accessor.snapshot.Cluster == nil || len(accessor.snapshot.Cluster.Subnets) != 2 {
+		t.Errorf("responses were not recorded in the snapshot")
+	}
+}
+
+func TestGetWorkersConvertsFetchedResponses(t *testing.T) {
+	values, err := newFakeAccessor(newFakeClients()).GetWorkers(context.Background(), api.NewConversionReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	small, ok := (*values.AWSWorkers)["small"]
+	if !ok {
+		t.Fatalf("workers = %+v, expected small", *values.AWSWorkers)
+	}
+	if small.Replicas != 2 || *small.Spec.InstanceType != "t3.large" || small.Spec.Scaling.MaxSize != 3 {
+		t.Errorf("small = %+v, expected 2 replicas of t3.large scaling up to 3", small)
+	}
+	if len(small.Spec.AvailabilityZones) != 2 || small.Spec.AvailabilityZones[1] != "eu-central-1b" {
+		t.Errorf("availability zones = %v, expected zones of subnet-1 and subnet-2", small.Spec.AvailabilityZones)
+	}
+}
+
+func TestPlanClusterTags(t *testing.T) {
+	tags := map[string]string{"team": "plural"}
+	plan := api.NewTagPlan(api.ClusterProviderAWS, tags)
+	if err := newFakeAccessor(newFakeClients()).PlanClusterTags(context.Background(), tags, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		kind    string
+		id      string
+		changes map[string]api.TagAction
+	}{
+		{kind: "eks-cluster", id: "arn:aws:eks:eu-central-1:123456789012:cluster/test", changes: map[string]api.TagAction{
+			"sigs.k8s.io/cluster-api-provider-aws/role": api.TagActionAdd,
+			"team": api.TagActionNone,
+		}},
+		{kind: "vpc", id: "vpc-1", changes: map[string]api.TagAction{
+			"sigs.k8s.io/cluster-api-provider-aws/role": api.TagActionAdd,
+			"team": api.TagActionAdd,
+		}},
+		{kind: "vpc-endpoint", id: "vpce-1", changes: map[string]api.TagAction{
+			"sigs.k8s.io/cluster-api-provider-aws/role": api.TagActionAdd,
+			"team": api.TagActionAdd,
+		}},
+		{kind: "route-table", id: "rtb-1", changes: map[string]api.TagAction{"team": api.TagActionAdd}},
+		{kind: "subnet", id: "subnet-1", changes: map[string]api.TagAction{
+			"kubernetes.io/role/internal-elb": api.TagActionNone,
+			"team":                            api.TagActionAdd,
+		}},
+		{kind: "nat-gateway", id: "nat-1", changes: map[string]api.TagAction{"team": api.TagActionAdd}},
+		{kind: "route-table", id: "rtb-2", changes: map[string]api.TagAction{"team": api.TagActionAdd}},
+		{kind: "subnet", id: "subnet-2", changes: map[string]api.TagAction{
+			"kubernetes.io/role/internal-elb": api.TagActionAdd,
+			"team":                            api.TagActionAdd,
+		}},
+		{kind: "security-group", id: "sg-2", changes: map[string]api.TagAction{"team": api.TagActionAdd}},
+	}
+
+	if len(plan.Resources) != len(expected) {
+		t.Fatalf("plan has %d resources, expected %d: %+v", len(plan.Resources), len(expected), plan.Resources)
+	}
+	for i, resource := range plan.Resources {
+		if resource.Kind != expected[i].kind || resource.ID != expected[i].id {
+			t.Errorf("resource %d is %s %s, expected %s %s", i, resource.Kind, resource.ID, expected[i].kind, expected[i].id)
+			continue
+		}
+
+		actions := map[string]api.TagAction{}
+		for _, change := range resource.Changes {
+			actions[change.Key] = change.Action
+		}
+		if len(actions) != len(expected[i].changes) {
+			t.Errorf("%s %s has changes %v, expected %v", resource.Kind, resource.ID, actions, expected[i].changes)
+			continue
+		}
+		for key, action := range expected[i].changes {
+			if actions[key] != action {
+				t.Errorf("%s %s has changes %v, expected %v", resource.Kind, resource.ID, actions, expected[i].changes)
+				break
+			}
+		}
+	}
+}
+
+func copyTags(tags map[string]map[string]string) map[string]map[string]string {
+	result := make(map[string]map[string]string, len(tags))
+	for id, resourceTags := range tags {
+		result[id] = make(map[string]string, len(resourceTags))
+		for key, value := range resourceTags {
+			result[id][key] = value
+		}
+	}
+
+	return result
+}
+
+// TestTagJournalResumesAndRevertsFailedRun stops tagging at subnet-1, then checks that the journal lists
+// the resources tagged before it, that the run resumes from the journal and that reverting it restores the original tags.
+func TestTagJournalResumesAndRevertsFailedRun(t *testing.T) {
+	ctx := context.Background()
+	ec2Client, eksClient := newFakeClients()
+	// The cluster has the tag with a different value, so that reverting has to write it back.
+	arn := *eksClient.cluster.Arn
+	eksClient.cluster.Tags = map[string]string{"team": "platform"}
+	eksClient.tags[arn] = map[string]string{"team": "platform"}
+	ec2Client.tagErrors = map[string]error{"subnet-1": &smithy.GenericAPIError{Code: "RequestLimitExceeded"}}
+	originalEC2, originalEKS := copyTags(ec2Client.tags), copyTags(eksClient.tags)
+
+	accessor := newFakeAccessor(ec2Client, eksClient)
+	tags := map[string]string{"team": "plural"}
+	plan := api.NewTagPlan(api.ClusterProviderAWS, tags)
+	if err := accessor.PlanClusterTags(ctx, tags, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	j := journal.New(filepath.Join(t.TempDir(), "journal.json"))
+	plan.SetJournal(j)
+	if err := accessor.ApplyTags(ctx, plan); err == nil {
+		t.Fatal("expected tagging of subnet-1 to fail")
+	}
+
+	recorded, err := j.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded == nil {
+		t.Fatal("journal was not recorded")
+	}
+	applied := map[string]bool{arn: true, "vpc-1": true, "vpce-1": true, "rtb-1": true}
+	for _, resource := range recorded.Resources {
+		if resource.Applied() != applied[resource.ID] {
+			t.Errorf("journal has %s %s applied = %t, expected %t", resource.Kind, resource.ID, resource.Applied(), applied[resource.ID])
+		}
+	}
+	if eksClient.tags[arn]["team"] != "plural" || ec2Client.tags["rtb-1"]["team"] != "plural" {
+		t.Errorf("resources before subnet-1 were not tagged: %v %v", eksClient.tags, ec2Client.tags)
+	}
+	if _, ok := ec2Client.tags["rtb-2"]; ok {
+		t.Errorf("rtb-2 after the failed subnet-1 was tagged: %v", ec2Client.tags["rtb-2"])
+	}
+
+	ec2Client.tagErrors = nil
+	// Resources tagged before are skipped, the cluster tag changed meanwhile must not be overwritten again.
+	eksClient.tags[arn]["team"] = "changed"
+	recorded.SetJournal(j)
+	if err = accessor.ApplyTags(ctx, recorded); err != nil {
+		t.Fatal(err)
+	}
+	if eksClient.tags[arn]["team"] != "changed" {
+		t.Errorf("resumed run tagged the cluster again")
+	}
+	if recorded.HasChanges() || ec2Client.tags["sg-2"]["team"] != "plural" {
+		t.Errorf("resumed run did not tag the remaining resources: %v", ec2Client.tags)
+	}
+	eksClient.tags[arn]["team"] = "plural"
+
+	if recorded, err = j.Load(); err != nil {
+		t.Fatal(err)
+	}
+	recorded.SetJournal(j)
+	if err = accessor.RemoveTags(ctx, recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ec2Client.tags, originalEC2) {
+		t.Errorf("EC2 tags after revert = %v, expected %v", ec2Client.tags, originalEC2)
+	}
+	if !reflect.DeepEqual(eksClient.tags, originalEKS) {
+		t.Errorf("EKS tags after revert = %v, expected %v", eksClient.tags, originalEKS)
+	}
+	if recorded, err = j.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if recorded.Applied() {
+		t.Errorf("journal still lists applied tags after revert")
+	}
+}
@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"context"
+
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// newClients creates EC2 and EKS clients from the default credential chain. Endpoints, proxy and CA bundle
+// are taken from the configuration when set.
+func newClients(ctx context.Context, configuration *api.AWSConfiguration) (*ec2.Client, *eks.Client, error) {
+	options := []func(*awsConfig.LoadOptions) error{awsConfig.WithRegion(configuration.Region)}
+	if !configuration.ClientOptions.IsZero() {
+		httpClient, err := configuration.HTTPClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, awsConfig.WithHTTPClient(httpClient))
+	}
+
+	cfg, err := awsConfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ec2Client := ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+		if endpoint := configuration.Endpoints.EC2; len(endpoint) > 0 {
+			o.BaseEndpoint = &endpoint
+		}
+	})
+	eksClient := eks.NewFromConfig(cfg, func(o *eks.Options) {
+		if endpoint := configuration.Endpoints.EKS; len(endpoint) > 0 {
+			o.BaseEndpoint = &endpoint
+		}
+	})
+
+	return ec2Client, eksClient, nil
+}
@@ -3,31 +3,59 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	tageks "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
-	"github.com/weaveworks/eksctl/pkg/actions/addon"
-	"github.com/weaveworks/eksctl/pkg/actions/nodegroup"
-	"github.com/weaveworks/eksctl/pkg/eks"
 	infrav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// EC2API is the part of the EC2 client used by Cluster.
+type EC2API interface {
+	DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error)
+	DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+	DescribeVpcEndpoints(ctx context.Context, params *ec2.DescribeVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error)
+	DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+	DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeNatGateways(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error)
+	DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+	CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	DeleteTags(ctx context.Context, params *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error)
+}
+
+// EKSAPI is the part of the EKS client used by Cluster.
+type EKSAPI interface {
+	eks.ListAddonsAPIClient
+	DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error)
+	DescribeAddon(ctx context.Context, params *eks.DescribeAddonInput, optFns ...func(*eks.Options)) (*eks.DescribeAddonOutput, error)
+	TagResource(ctx context.Context, params *eks.TagResourceInput, optFns ...func(*eks.Options)) (*eks.TagResourceOutput, error)
+	UntagResource(ctx context.Context, params *eks.UntagResourceInput, optFns ...func(*eks.Options)) (*eks.UntagResourceOutput, error)
+}
+
 type Cluster struct {
-	configuration     *api.AWSConfiguration
-	ctx               context.Context
-	ClusterProvider   *eks.ClusterProvider
-	NodeGroupProvider *nodegroup.Manager
-	AddonProvider     *addon.Manager
+	configuration *api.AWSConfiguration
+	ec2Client     EC2API
+	eksClient     EKSAPI
 }
 
-func (this *Cluster) AddClusterTags(tags map[string]string) error {
-	cluster, err := this.ClusterProvider.GetCluster(this.ctx, this.configuration.ClusterName)
+const (
+	resourceKindCluster       = "eks-cluster"
+	resourceKindVPC           = "vpc"
+	resourceKindVPCEndpoint   = "vpc-endpoint"
+	resourceKindSubnet        = "subnet"
+	resourceKindRouteTable    = "route-table"
+	resourceKindNatGateway    = "nat-gateway"
+	resourceKindSecurityGroup = "security-group"
+)
+
+func (this *Cluster) PlanClusterTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	cluster, err := this.describeCluster(ctx)
 	if err != nil {
 		return err
 	}
@@ -35,21 +63,10 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 	for k, v := range tags {
 		clusterTags[k] = v
 	}
-	_, err = this.ClusterProvider.AWSProvider.EKS().TagResource(this.ctx, &tageks.TagResourceInput{
-		ResourceArn: cluster.Arn,
-		Tags:        clusterTags,
-	})
-	if err != nil {
-		return err
-	}
-	cfg, err := awsConfig.LoadDefaultConfig(this.ctx)
-	if err != nil {
-		return err
-	}
-	cfg.Region = this.configuration.Region
-	svc := ec2.NewFromConfig(cfg)
+	plan.Add(resourceKindCluster, *cluster.Arn, cluster.Tags, clusterTags)
+
 	name := "vpc-id"
-	vpcs, err := svc.DescribeVpcs(this.ctx, &ec2.DescribeVpcsInput{
+	vpcs, err := this.ec2Client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
 		Filters: []ec2Types.Filter{
 			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
 		},
@@ -61,18 +78,9 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 		return fmt.Errorf("couldn't find the VPC %s", *cluster.ResourcesVpcConfig.VpcId)
 	}
 	vpc := vpcs.Vpcs[0]
-	dryFalse := false
-
-	_, err = this.ClusterProvider.AWSProvider.EC2().CreateTags(this.ctx, &ec2.CreateTagsInput{
-		Resources: []string{*vpc.VpcId},
-		Tags:      convertTags(clusterTags),
-		DryRun:    &dryFalse,
-	})
-	if err != nil {
-		return err
-	}
+	plan.Add(resourceKindVPC, *vpc.VpcId, ec2TagMap(vpc.Tags), clusterTags)
 
-	vpce, err := svc.DescribeVpcEndpoints(this.ctx, &ec2.DescribeVpcEndpointsInput{
+	vpce, err := this.ec2Client.DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
 		Filters: []ec2Types.Filter{
 			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
 		},
@@ -81,21 +89,17 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 		return err
 	}
 	for _, endpoint := range vpce.VpcEndpoints {
-		_, err = this.ClusterProvider.AWSProvider.EC2().CreateTags(this.ctx, &ec2.CreateTagsInput{
-			Resources: []string{*endpoint.VpcEndpointId},
-			Tags:      convertTags(clusterTags),
-			DryRun:    &dryFalse,
-		})
-		if err != nil {
-			return err
-		}
+		plan.Add(resourceKindVPCEndpoint, *endpoint.VpcEndpointId, ec2TagMap(endpoint.Tags), clusterTags)
 	}
 
-	subnets, err := svc.DescribeSubnets(this.ctx, &ec2.DescribeSubnetsInput{
+	subnets, err := this.ec2Client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
 		Filters: []ec2Types.Filter{
 			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
 		},
 	})
+	if err != nil {
+		return err
+	}
 
 	subnetTags := map[string]string{"kubernetes.io/role/internal-elb": "1"}
 	for k, v := range tags {
@@ -103,7 +107,7 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 	}
 	for _, subnet := range subnets.Subnets {
 		subnetID := "association.subnet-id"
-		rt, err := svc.DescribeRouteTables(this.ctx, &ec2.DescribeRouteTablesInput{
+		rt, err := this.ec2Client.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{
 			Filters: []ec2Types.Filter{
 				{Name: &subnetID, Values: []string{*subnet.SubnetId}},
 			},
@@ -112,27 +116,13 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 			return err
 		}
 		if len(rt.RouteTables) > 0 {
-			_, err = this.ClusterProvider.AWSProvider.EC2().CreateTags(this.ctx, &ec2.CreateTagsInput{
-				Resources: []string{*rt.RouteTables[0].RouteTableId},
-				Tags:      convertTags(tags),
-				DryRun:    &dryFalse,
-			})
-			if err != nil {
-				return err
-			}
+			plan.Add(resourceKindRouteTable, *rt.RouteTables[0].RouteTableId, ec2TagMap(rt.RouteTables[0].Tags), tags)
 		}
 
-		_, err = this.ClusterProvider.AWSProvider.EC2().CreateTags(this.ctx, &ec2.CreateTagsInput{
-			Resources: []string{*subnet.SubnetId},
-			Tags:      convertTags(subnetTags),
-			DryRun:    &dryFalse,
-		})
-		if err != nil {
-			return err
-		}
+		plan.Add(resourceKindSubnet, *subnet.SubnetId, ec2TagMap(subnet.Tags), subnetTags)
 
 		subnetID = "subnet-id"
-		gtws, err := svc.DescribeNatGateways(this.ctx, &ec2.DescribeNatGatewaysInput{
+		gtws, err := this.ec2Client.DescribeNatGateways(ctx, &ec2.DescribeNatGatewaysInput{
 			Filter: []ec2Types.Filter{
 				{Name: &subnetID, Values: []string{*subnet.SubnetId}},
 			},
@@ -142,18 +132,11 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 		}
 
 		for _, gtw := range gtws.NatGateways {
-			_, err = this.ClusterProvider.AWSProvider.EC2().CreateTags(this.ctx, &ec2.CreateTagsInput{
-				Resources: []string{*gtw.NatGatewayId},
-				Tags:      convertTags(tags),
-				DryRun:    &dryFalse,
-			})
-			if err != nil {
-				return err
-			}
+			plan.Add(resourceKindNatGateway, *gtw.NatGatewayId, ec2TagMap(gtw.Tags), tags)
 		}
 
 	}
-	sgroups, err := svc.DescribeSecurityGroups(this.ctx, &ec2.DescribeSecurityGroupsInput{
+	sgroups, err := this.ec2Client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
 		Filters: []ec2Types.Filter{
 			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
 		},
@@ -164,36 +147,117 @@ func (this *Cluster) AddClusterTags(tags map[string]string) error {
 
 	for _, sg := range sgroups.SecurityGroups {
 		if *sg.GroupName != "default" {
-			_, err = this.ClusterProvider.AWSProvider.EC2().CreateTags(this.ctx, &ec2.CreateTagsInput{
-				Resources: []string{*sg.GroupId},
-				Tags:      convertTags(tags),
-				DryRun:    &dryFalse,
-			})
-			if err != nil {
-				return err
-			}
+			plan.Add(resourceKindSecurityGroup, *sg.GroupId, ec2TagMap(sg.Tags), tags)
 		}
 	}
 
 	return nil
 }
 
-func (this *Cluster) GetCluster() (*api.Cluster, error) {
-	cluster, err := this.ClusterProvider.GetCluster(this.ctx, this.configuration.ClusterName)
+// ApplyTags writes added and changed tags of the planned resources. EKS cluster and node groups
+// are identified by ARN, all other resources by EC2 resource ID.
+func (this *Cluster) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	return plan.Apply(func(resource api.ResourceTagPlan) error {
+		return this.createTags(ctx, resource.ID, resource.Tags())
+	})
+}
+
+// RemoveTags deletes tags added by the plan and writes back previous values of the overwritten ones.
+func (this *Cluster) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	return plan.Revert(func(resource api.ResourceTagPlan) error {
+		if err := this.deleteTags(ctx, resource.ID, resource.RemovedKeys()); err != nil {
+			return err
+		}
+
+		return this.createTags(ctx, resource.ID, resource.RestoredTags())
+	})
+}
+
+func (this *Cluster) createTags(ctx context.Context, id string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if strings.HasPrefix(id, "arn:") {
+		_, err := this.eksClient.TagResource(ctx, &eks.TagResourceInput{
+			ResourceArn: &id,
+			Tags:        tags,
+		})
+		return err
+	}
+
+	dryFalse := false
+	_, err := this.ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{id},
+		Tags:      convertTags(tags),
+		DryRun:    &dryFalse,
+	})
+	return err
+}
+
+func (this *Cluster) deleteTags(ctx context.Context, id string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if strings.HasPrefix(id, "arn:") {
+		_, err := this.eksClient.UntagResource(ctx, &eks.UntagResourceInput{
+			ResourceArn: &id,
+			TagKeys:     keys,
+		})
+		return err
+	}
+
+	ec2Tags := []types.Tag{}
+	for _, k := range keys {
+		key := strings.Clone(k)
+		ec2Tags = append(ec2Tags, types.Tag{Key: &key})
+	}
+
+	dryFalse := false
+	_, err := this.ec2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		Resources: []string{id},
+		Tags:      ec2Tags,
+		DryRun:    &dryFalse,
+	})
+	return err
+}
+
+// Snapshot holds raw responses of the EKS and EC2 APIs the cluster is converted from.
+type Snapshot struct {
+	Cluster           *ekstypes.Cluster                `json:"cluster"`
+	Addons            []ekstypes.Addon                 `json:"addons"`
+	AvailabilityZones []ec2Types.AvailabilityZone      `json:"availabilityZones"`
+	VPC               ec2Types.Vpc                     `json:"vpc"`
+	Subnets           []ec2Types.Subnet                `json:"subnets"`
+	RouteTables       map[string][]ec2Types.RouteTable `json:"routeTables"`
+	NatGateways       map[string][]ec2Types.NatGateway `json:"natGateways"`
+	SecurityGroups    []ec2Types.SecurityGroup         `json:"securityGroups"`
+}
+
+func (this *Cluster) GetCluster(ctx context.Context, report *api.ConversionReport) (*api.Cluster, error) {
+	snapshot, err := this.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.Convert(snapshot, report)
+}
+
+// Fetch reads all responses the cluster is converted from. Route tables and NAT gateways are stored by subnet ID.
+func (this *Cluster) Fetch(ctx context.Context) (*Snapshot, error) {
+	cluster, err := this.describeCluster(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	addons, err := this.AddonProvider.GetAll(this.ctx)
+	addons, err := this.describeAddons(ctx)
 	if err != nil {
 		return nil, err
 	}
-	cfg, err := awsConfig.LoadDefaultConfig(this.ctx)
-	cfg.Region = this.configuration.Region
-	svc := ec2.NewFromConfig(cfg)
 
 	name := "vpc-id"
-	subnets, err := svc.DescribeSubnets(this.ctx, &ec2.DescribeSubnetsInput{
+	subnets, err := this.ec2Client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
 		Filters: []ec2Types.Filter{
 			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
 		},
@@ -202,7 +266,7 @@ func (this *Cluster) GetCluster() (*api.Cluster, error) {
 		return nil, err
 	}
 	regionName := "region-name"
-	az, err := svc.DescribeAvailabilityZones(this.ctx, &ec2.DescribeAvailabilityZonesInput{
+	az, err := this.ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
 		Filters: []ec2Types.Filter{
 			{Name: &regionName, Values: []string{this.configuration.Region}},
 		},
@@ -210,9 +274,8 @@ func (this *Cluster) GetCluster() (*api.Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	azLimit := len(az.AvailabilityZones)
 
-	vpcs, err := svc.DescribeVpcs(this.ctx, &ec2.DescribeVpcsInput{
+	vpcs, err := this.ec2Client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
 		Filters: []ec2Types.Filter{
 			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
 		},
@@ -223,12 +286,67 @@ func (this *Cluster) GetCluster() (*api.Cluster, error) {
 	if len(vpcs.Vpcs) != 1 {
 		return nil, fmt.Errorf("couldn't find the VPC %s", *cluster.ResourcesVpcConfig.VpcId)
 	}
-	vpc := vpcs.Vpcs[0]
+
+	snapshot := &Snapshot{
+		Cluster:           cluster,
+		Addons:            addons,
+		AvailabilityZones: az.AvailabilityZones,
+		VPC:               vpcs.Vpcs[0],
+		Subnets:           subnets.Subnets,
+		RouteTables:       map[string][]ec2Types.RouteTable{},
+		NatGateways:       map[string][]ec2Types.NatGateway{},
+	}
+	for _, subnet := range subnets.Subnets {
+		subnetID := "association.subnet-id"
+		rt, err := this.ec2Client.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{
+			Filters: []ec2Types.Filter{
+				{Name: &subnetID, Values: []string{*subnet.SubnetId}},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		snapshot.RouteTables[*subnet.SubnetId] = rt.RouteTables
+
+		subnetID = "subnet-id"
+		gtw, err := this.ec2Client.DescribeNatGateways(ctx, &ec2.DescribeNatGatewaysInput{
+			Filter: []ec2Types.Filter{
+				{Name: &subnetID, Values: []string{*subnet.SubnetId}},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		snapshot.NatGateways[*subnet.SubnetId] = gtw.NatGateways
+	}
+
+	sgroups, err := this.ec2Client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []ec2Types.Filter{
+			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot.SecurityGroups = sgroups.SecurityGroups
+
+	return snapshot, nil
+}
+
+// Convert builds cluster values from the responses without calling AWS APIs.
+func (this *Cluster) Convert(snapshot *Snapshot, report *api.ConversionReport) (*api.Cluster, error) {
+	cluster := snapshot.Cluster
+	if cluster == nil {
+		return nil, fmt.Errorf("EKS cluster %s not found in the responses", this.configuration.ClusterName)
+	}
+	addons := snapshot.Addons
+	azLimit := len(snapshot.AvailabilityZones)
+	vpc := snapshot.VPC
 	newCluster := &api.Cluster{
 		Name:              this.configuration.ClusterName,
 		PodCIDRBlocks:     []string{*vpc.CidrBlock},
 		ServiceCIDRBlocks: []string{},
-		KubernetesVersion: fmt.Sprintf("v%s", *cluster.Version),
+		KubernetesVersion: api.KubernetesVersion(*cluster.Version),
 		CloudSpec: api.CloudSpec{
 			AWSCloudSpec: &api.AWSCloudSpec{
 				Region:             this.configuration.Region,
@@ -302,37 +420,19 @@ func (this *Cluster) GetCluster() (*api.Cluster, error) {
 	}
 	for _, addon := range addons {
 		newCluster.AWSCloudSpec.Addons = append(newCluster.AWSCloudSpec.Addons, api.Addon{
-			Name:               addon.Name,
-			Version:            addon.Version,
+			Name:               *addon.AddonName,
+			Version:            *addon.AddonVersion,
 			ConflictResolution: api.AddonResolutionOverwrite,
 		})
 	}
-	for _, subnet := range subnets.Subnets {
-		subnetID := "association.subnet-id"
-		rt, err := svc.DescribeRouteTables(this.ctx, &ec2.DescribeRouteTablesInput{
-			Filters: []ec2Types.Filter{
-				{Name: &subnetID, Values: []string{*subnet.SubnetId}},
-			},
-		})
-		if err != nil {
-			return nil, err
-		}
+	for _, subnet := range snapshot.Subnets {
 		var rtID *string
-		if len(rt.RouteTables) > 0 {
-			rtID = rt.RouteTables[0].RouteTableId
-		}
-		subnetID = "subnet-id"
-		gtw, err := svc.DescribeNatGateways(this.ctx, &ec2.DescribeNatGatewaysInput{
-			Filter: []ec2Types.Filter{
-				{Name: &subnetID, Values: []string{*subnet.SubnetId}},
-			},
-		})
-		if err != nil {
-			return nil, err
+		if rt := snapshot.RouteTables[*subnet.SubnetId]; len(rt) > 0 {
+			rtID = rt[0].RouteTableId
 		}
 		var gtID *string
-		if len(gtw.NatGateways) > 0 {
-			gtID = gtw.NatGateways[0].NatGatewayId
+		if gtw := snapshot.NatGateways[*subnet.SubnetId]; len(gtw) > 0 {
+			gtID = gtw[0].NatGatewayId
 		}
 		sub := infrav1.SubnetSpec{
 			ID:               *subnet.SubnetId,
@@ -349,29 +449,69 @@ func (this *Cluster) GetCluster() (*api.Cluster, error) {
 		newCluster.AWSCloudSpec.NetworkSpec.Subnets = append(newCluster.AWSCloudSpec.NetworkSpec.Subnets, sub)
 	}
 
-	sgroups, err := svc.DescribeSecurityGroups(this.ctx, &ec2.DescribeSecurityGroupsInput{
-		Filters: []ec2Types.Filter{
-			{Name: &name, Values: []string{*cluster.ResourcesVpcConfig.VpcId}},
-		},
+	if len(snapshot.SecurityGroups) > 0 {
+		newCluster.AWSCloudSpec.NetworkSpec.SecurityGroupOverrides = map[infrav1.SecurityGroupRole]string{}
+	}
+
+	reportCluster(report, cluster, len(snapshot.SecurityGroups))
+	return newCluster, nil
+}
+
+// NewAWSCluster creates cluster that reads the EKS cluster with the given clients. Clients can be nil if only Convert is used.
+func NewAWSCluster(configuration *api.AWSConfiguration, ec2Client EC2API, eksClient EKSAPI) *Cluster {
+	return &Cluster{
+		configuration: configuration,
+		ec2Client:     ec2Client,
+		eksClient:     eksClient,
+	}
+}
+
+func (this *Cluster) describeCluster(ctx context.Context) (*ekstypes.Cluster, error) {
+	output, err := this.eksClient.DescribeCluster(ctx, &eks.DescribeClusterInput{
+		Name: &this.configuration.ClusterName,
 	})
 	if err != nil {
 		return nil, err
 	}
-	if len(sgroups.SecurityGroups) > 0 {
-		newCluster.AWSCloudSpec.NetworkSpec.SecurityGroupOverrides = map[infrav1.SecurityGroupRole]string{}
+
+	return output.Cluster, nil
+}
+
+func (this *Cluster) describeAddons(ctx context.Context) ([]ekstypes.Addon, error) {
+	result := make([]ekstypes.Addon, 0)
+	paginator := eks.NewListAddonsPaginator(this.eksClient, &eks.ListAddonsInput{
+		ClusterName: &this.configuration.ClusterName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range page.Addons {
+			output, err := this.eksClient.DescribeAddon(ctx, &eks.DescribeAddonInput{
+				ClusterName: &this.configuration.ClusterName,
+				AddonName:   &name,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, *output.Addon)
+		}
 	}
 
-	return newCluster, nil
+	return result, nil
 }
 
-func NewAWSCluster(ctx context.Context, configuration *api.AWSConfiguration, clusterProvider *eks.ClusterProvider, nodeGroupProvider *nodegroup.Manager, addonProvider *addon.Manager) *Cluster {
-	return &Cluster{
-		configuration:     configuration,
-		ctx:               ctx,
-		ClusterProvider:   clusterProvider,
-		NodeGroupProvider: nodeGroupProvider,
-		AddonProvider:     addonProvider,
+func ec2TagMap(tags []types.Tag) map[string]string {
+	result := map[string]string{}
+	for _, tag := range tags {
+		if tag.Key != nil && tag.Value != nil {
+			result[*tag.Key] = *tag.Value
+		}
 	}
+
+	return result
 }
 
 func convertTags(tags map[string]string) []types.Tag {
@@ -384,5 +524,8 @@ func convertTags(tags map[string]string) []types.Tag {
 			Value: &value,
 		})
 	}
+	sort.Slice(ec2Tags, func(i, j int) bool {
+		return *ec2Tags[i].Key < *ec2Tags[j].Key
+	})
 	return ec2Tags
 }
@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"strings"
+
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+func clusterPath(elements ...string) string {
+	return api.Path(append([]string{"cluster", "aws"}, elements...)...)
+}
+
+// reportCluster records values of converted cluster that were not read from the EKS cluster.
+func reportCluster(report *api.ConversionReport, cluster *ekstypes.Cluster, securityGroups int) {
+	report.Approximated(api.Path("cluster", "podCidrBlocks"), "VPC CIDR block is used as pod CIDR block")
+	report.Defaulted(clusterPath("sshKeyName"), "bastion SSH key name is always set to default")
+	report.Defaulted(clusterPath("identityRef"), "default controller identity is used")
+	report.Defaulted(clusterPath("secondaryCidrBlock"), "secondary CIDR block is not read from the VPC")
+
+	if vpcConfig := cluster.ResourcesVpcConfig; vpcConfig != nil && (!vpcConfig.EndpointPublicAccess || vpcConfig.EndpointPrivateAccess) {
+		report.Defaulted(clusterPath("endpointAccess"),
+			"endpoint access is set to public only, but cluster has public access %t and private access %t",
+			vpcConfig.EndpointPublicAccess, vpcConfig.EndpointPrivateAccess)
+	}
+
+	if cluster.KubernetesNetworkConfig != nil && cluster.KubernetesNetworkConfig.ServiceIpv4Cidr != nil {
+		report.Dropped(api.Path("cluster", "serviceCidrBlocks"),
+			"cluster service CIDR block %s is not converted", *cluster.KubernetesNetworkConfig.ServiceIpv4Cidr)
+	}
+
+	if enabled := enabledLogTypes(cluster.Logging); len(enabled) > 0 {
+		report.Dropped(clusterPath("logging"), "enabled control plane logs %s are not converted", strings.Join(enabled, ", "))
+	}
+
+	if len(cluster.EncryptionConfig) > 0 {
+		report.Dropped(clusterPath("encryptionConfig"), "secrets encryption configuration is not converted")
+	}
+
+	if len(cluster.Tags) > 0 {
+		report.Dropped(clusterPath("additionalTags"), "%d EKS cluster tags are not converted", len(cluster.Tags))
+	}
+
+	if securityGroups > 0 {
+		report.Dropped(clusterPath("network", "securityGroupOverrides"),
+			"%d security groups found in the VPC are not set as overrides", securityGroups)
+	}
+
+	report.Defaulted(clusterPath("addons"), "conflict resolution of all addons is set to %s", api.AddonResolutionOverwrite)
+}
+
+func enabledLogTypes(logging *ekstypes.Logging) []string {
+	result := make([]string, 0)
+	if logging == nil {
+		return result
+	}
+
+	for _, setup := range logging.ClusterLogging {
+		if setup.Enabled == nil || !*setup.Enabled {
+			continue
+		}
+
+		for _, logType := range setup.Types {
+			result = append(result, string(logType))
+		}
+	}
+
+	return result
+}
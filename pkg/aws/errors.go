@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/smithy-go"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// wrapError converts errors returned by AWS SDKs into typed API errors.
+func wrapError(resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return api.NewError(errorClass(err), resource, err)
+}
+
+func errorClass(err error) api.ErrorClass {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return errorCodeClass(apiErr.ErrorCode())
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return errorCodeClass(awsErr.Code())
+	}
+
+	return api.ErrorClassUnknown
+}
+
+func errorCodeClass(code string) api.ErrorClass {
+	switch {
+	case code == "ResourceNotFoundException", code == "NotFoundException", strings.HasSuffix(code, ".NotFound"):
+		return api.ErrorClassNotFound
+	case code == "AccessDenied", code == "AccessDeniedException", code == "UnauthorizedOperation",
+		code == "UnrecognizedClientException", code == "AuthFailure", code == "ExpiredToken", code == "ExpiredTokenException":
+		return api.ErrorClassPermissionDenied
+	case code == "Throttling", code == "ThrottlingException", code == "RequestLimitExceeded",
+		code == "TooManyRequestsException", code == "RequestThrottled":
+		return api.ErrorClassThrottled
+	}
+
+	return api.ErrorClassUnknown
+}
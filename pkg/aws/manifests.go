@@ -0,0 +1,456 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	ekscontrolplanev1 "sigs.k8s.io/cluster-api-provider-aws/v2/controlplane/eks/api/v1beta2"
+	expinfrav1 "sigs.k8s.io/cluster-api-provider-aws/v2/exp/api/v1beta2"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+)
+
+// renderManifests returns Cluster, AWSManagedCluster, AWSManagedControlPlane and a MachinePool
+// with AWSManagedMachinePool for every worker. EKS cluster and node group names are set explicitly,
+// so that CAPA adopts the existing ones instead of creating new.
+func renderManifests(values *api.Values, report *api.ConversionReport) ([]runtime.Object, error) {
+	spec := values.Cluster.AWSCloudSpec
+	if spec == nil {
+		return nil, fmt.Errorf("values do not contain aws cluster")
+	}
+
+	name := values.Cluster.Name
+	controlPlaneName := fmt.Sprintf("%s-control-plane", name)
+
+	managedCluster := &infrav1.AWSManagedCluster{
+		TypeMeta:   manifests.TypeMeta(infrav1.GroupVersion.WithKind("AWSManagedCluster")),
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+
+	controlPlane := &ekscontrolplanev1.AWSManagedControlPlane{
+		TypeMeta:   manifests.TypeMeta(ekscontrolplanev1.GroupVersion.WithKind("AWSManagedControlPlane")),
+		ObjectMeta: metav1.ObjectMeta{Name: controlPlaneName},
+		Spec:       controlPlaneSpec(name, values.Cluster.KubernetesVersion, spec),
+	}
+
+	cluster := manifests.NewCluster(
+		values.Cluster,
+		spec.Labels,
+		manifests.Reference(ekscontrolplanev1.GroupVersion.WithKind("AWSManagedControlPlane"), controlPlaneName),
+		manifests.Reference(infrav1.GroupVersion.WithKind("AWSManagedCluster"), name),
+	)
+
+	result := []runtime.Object{cluster, managedCluster, controlPlane}
+	if values.Workers.AWSWorkers == nil {
+		return result, nil
+	}
+
+	workers := *values.Workers.AWSWorkers
+	names := make([]string, 0, len(workers))
+	for workerName := range workers {
+		names = append(names, workerName)
+	}
+	sort.Strings(names)
+
+	version := manifests.MachinePoolVersion(values.Cluster.KubernetesVersion, "cluster.kubernetesVersion", report)
+	for _, workerName := range names {
+		worker := workers[workerName]
+		if worker == nil {
+			continue
+		}
+
+		poolName := fmt.Sprintf("%s-%s", name, workerName)
+		pool := &expinfrav1.AWSManagedMachinePool{
+			TypeMeta:   manifests.TypeMeta(expinfrav1.GroupVersion.WithKind("AWSManagedMachinePool")),
+			ObjectMeta: metav1.ObjectMeta{Name: poolName},
+			Spec:       machinePoolSpec(workerName, worker.Spec),
+		}
+
+		machinePool := manifests.NewMachinePool(
+			name,
+			poolName,
+			resources.Ptr(int32(worker.Replicas)),
+			version,
+			api.StringTags(worker.Labels),
+			worker.Annotations,
+			manifests.Reference(expinfrav1.GroupVersion.WithKind("AWSManagedMachinePool"), poolName),
+		)
+
+		result = append(result, machinePool, pool)
+	}
+
+	return result, nil
+}
+
+func controlPlaneSpec(name, version string, spec *api.AWSCloudSpec) ekscontrolplanev1.AWSManagedControlPlaneSpec {
+	result := ekscontrolplanev1.AWSManagedControlPlaneSpec{
+		EKSClusterName:       name,
+		IdentityRef:          spec.IdentityRef,
+		NetworkSpec:          spec.NetworkSpec,
+		Region:               spec.Region,
+		AdditionalTags:       spec.AdditionalTags,
+		ControlPlaneEndpoint: spec.ControlPlaneEndpoint,
+		Bastion:              spec.Bastion,
+		Logging: &ekscontrolplanev1.ControlPlaneLoggingSpec{
+			APIServer:         spec.Logging.APIServer,
+			Audit:             spec.Logging.Audit,
+			Authenticator:     spec.Logging.Authenticator,
+			ControllerManager: spec.Logging.ControllerManager,
+			Scheduler:         spec.Logging.Scheduler,
+		},
+		EndpointAccess: ekscontrolplanev1.EndpointAccess{
+			Public:  resources.Ptr(spec.EndpointAccess.Public),
+			Private: resources.Ptr(spec.EndpointAccess.Private),
+		},
+		AssociateOIDCProvider: spec.AssociateOIDCProvider,
+		VpcCni: ekscontrolplanev1.VpcCni{
+			Disable: spec.VpcCni.Disable,
+			Env:     spec.VpcCni.Env,
+		},
+		KubeProxy: ekscontrolplanev1.KubeProxy{Disable: spec.KubeProxy.Disable},
+	}
+
+	if len(spec.Version) > 0 {
+		version = spec.Version
+	}
+	if len(version) > 0 {
+		result.Version = resources.Ptr(api.KubernetesVersion(version))
+	}
+	if len(spec.SecondaryCidrBlock) > 0 {
+		result.SecondaryCidrBlock = resources.Ptr(spec.SecondaryCidrBlock)
+	}
+	if len(spec.SSHKeyName) > 0 {
+		result.SSHKeyName = resources.Ptr(spec.SSHKeyName)
+	}
+	if len(spec.RoleName) > 0 {
+		result.RoleName = resources.Ptr(spec.RoleName)
+	}
+	if len(spec.RoleAdditionalPolicies) > 0 {
+		result.RoleAdditionalPolicies = resources.Ptr(spec.RoleAdditionalPolicies)
+	}
+	if len(spec.TokenMethod) > 0 {
+		result.TokenMethod = resources.Ptr(ekscontrolplanev1.EKSTokenMethod(spec.TokenMethod))
+	}
+
+	for _, cidr := range spec.EndpointAccess.PublicCIDRs {
+		result.EndpointAccess.PublicCIDRs = append(result.EndpointAccess.PublicCIDRs, resources.Ptr(cidr))
+	}
+
+	if len(spec.EncryptionConfig.Provider) > 0 {
+		result.EncryptionConfig = &ekscontrolplanev1.EncryptionConfig{Provider: resources.Ptr(spec.EncryptionConfig.Provider)}
+		for _, resource := range spec.EncryptionConfig.Resources {
+			result.EncryptionConfig.Resources = append(result.EncryptionConfig.Resources, resources.Ptr(resource))
+		}
+	}
+
+	if len(spec.IAMAuthenticatorConfig.RoleMappings) > 0 || len(spec.IAMAuthenticatorConfig.UserMappings) > 0 {
+		result.IAMAuthenticatorConfig = &ekscontrolplanev1.IAMAuthenticatorConfig{}
+		for _, mapping := range spec.IAMAuthenticatorConfig.RoleMappings {
+			result.IAMAuthenticatorConfig.RoleMappings = append(result.IAMAuthenticatorConfig.RoleMappings, ekscontrolplanev1.RoleMapping{
+				RoleARN:           mapping.RoleARN,
+				KubernetesMapping: ekscontrolplanev1.KubernetesMapping(mapping.KubernetesMapping),
+			})
+		}
+		for _, mapping := range spec.IAMAuthenticatorConfig.UserMappings {
+			result.IAMAuthenticatorConfig.UserMappings = append(result.IAMAuthenticatorConfig.UserMappings, ekscontrolplanev1.UserMapping{
+				UserARN:           mapping.UserARN,
+				KubernetesMapping: ekscontrolplanev1.KubernetesMapping(mapping.KubernetesMapping),
+			})
+		}
+	}
+
+	if oidc := spec.OIDCIdentityProviderConfig; len(oidc.IssuerURL) > 0 {
+		result.OIDCIdentityProviderConfig = &ekscontrolplanev1.OIDCIdentityProviderConfig{
+			ClientID:                   oidc.ClientID,
+			GroupsClaim:                oidc.GroupsClaim,
+			GroupsPrefix:               oidc.GroupsPrefix,
+			IdentityProviderConfigName: oidc.IdentityProviderConfigName,
+			IssuerURL:                  oidc.IssuerURL,
+			RequiredClaims:             oidc.RequiredClaims,
+			UsernameClaim:              oidc.UsernameClaim,
+			UsernamePrefix:             oidc.UsernamePrefix,
+			Tags:                       oidc.Tags,
+		}
+	}
+
+	if len(spec.Addons) > 0 {
+		addons := make([]ekscontrolplanev1.Addon, 0, len(spec.Addons))
+		for _, addon := range spec.Addons {
+			a := ekscontrolplanev1.Addon{Name: addon.Name, Version: addon.Version}
+			if len(addon.ConflictResolution) > 0 {
+				a.ConflictResolution = resources.Ptr(ekscontrolplanev1.AddonResolution(addon.ConflictResolution))
+			}
+			addons = append(addons, a)
+		}
+		result.Addons = &addons
+	}
+
+	return result
+}
+
+func machinePoolSpec(name string, spec api.AWSWorkerSpec) expinfrav1.AWSManagedMachinePoolSpec {
+	result := expinfrav1.AWSManagedMachinePoolSpec{
+		EKSNodegroupName:       name,
+		AvailabilityZones:      spec.AvailabilityZones,
+		AdditionalTags:         spec.AdditionalTags,
+		RoleAdditionalPolicies: spec.RoleAdditionalPolicies,
+		Labels:                 api.StringTags(spec.Labels),
+		InstanceType:           spec.InstanceType,
+	}
+
+	for _, subnetID := range spec.SubnetIDs {
+		if subnetID != nil {
+			result.SubnetIDs = append(result.SubnetIDs, *subnetID)
+		}
+	}
+
+	if len(spec.AMIVersion) > 0 {
+		result.AMIVersion = resources.Ptr(spec.AMIVersion)
+	}
+	if len(spec.AMIType) > 0 {
+		result.AMIType = resources.Ptr(expinfrav1.ManagedMachineAMIType(spec.AMIType))
+	}
+	if len(spec.CapacityType) > 0 {
+		result.CapacityType = resources.Ptr(expinfrav1.ManagedMachinePoolCapacityType(spec.CapacityType))
+	}
+	if spec.DiskSize > 0 {
+		result.DiskSize = resources.Ptr(spec.DiskSize)
+	}
+	if spec.Scaling != nil {
+		result.Scaling = &expinfrav1.ManagedMachinePoolScaling{
+			MinSize: resources.Ptr(spec.Scaling.MinSize),
+			MaxSize: resources.Ptr(spec.Scaling.MaxSize),
+		}
+	}
+	if spec.UpdateConfig != nil {
+		result.UpdateConfig = &expinfrav1.UpdateConfig{
+			MaxUnavailable:           spec.UpdateConfig.MaxUnavailable,
+			MaxUnavailablePercentage: spec.UpdateConfig.MaxUnavailablePercentage,
+		}
+	}
+
+	for _, taint := range spec.Taints {
+		result.Taints = append(result.Taints, expinfrav1.Taint{
+			Effect: expinfrav1.TaintEffect(taint.Effect),
+			Key:    taint.Key,
+			Value:  taint.Value,
+		})
+	}
+
+	return result
+}
+
+// readManifests rebuilds values from CAPA objects, the reverse of renderManifests.
+func readManifests(objects *api.ClusterObjects, report *api.ConversionReport) (*api.Values, error) {
+	controlPlane := &ekscontrolplanev1.AWSManagedControlPlane{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(objects.ControlPlane.Object, controlPlane); err != nil {
+		return nil, err
+	}
+
+	path := manifests.ObjectPath(objects.ControlPlane, "spec")
+	values := &api.Values{Cluster: manifests.ReadCluster(objects.Cluster)}
+	values.Cluster.AWSCloudSpec = readControlPlaneSpec(controlPlane.Spec, path, report)
+	values.Cluster.AWSCloudSpec.Labels = manifests.UserMetadata(objects.Cluster.Labels)
+	// Convert keeps the version only in the cluster, so the control plane version is not repeated in the spec.
+	if controlPlane.Spec.Version != nil {
+		values.Cluster.KubernetesVersion = api.KubernetesVersion(*controlPlane.Spec.Version)
+	}
+
+	if name := controlPlane.Spec.EKSClusterName; len(name) > 0 && name != values.Cluster.Name {
+		report.Approximated(api.Path(path, "eksClusterName"), "EKS cluster %s is named after the Cluster %s in the values", name, values.Cluster.Name)
+	}
+
+	workers := api.AWSWorkers{}
+	for _, machinePool := range objects.MachinePools {
+		pool := &expinfrav1.AWSManagedMachinePool{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(machinePool.Infrastructure.Object, pool); err != nil {
+			return nil, err
+		}
+
+		labels, annotations := manifests.WorkerMetadata(machinePool.MachinePool)
+		worker := &api.AWSWorker{
+			Labels:      api.PointerTags(labels),
+			Annotations: annotations,
+			Spec:        readMachinePoolSpec(pool.Spec, manifests.ObjectPath(machinePool.Infrastructure, "spec"), report),
+		}
+		if replicas := machinePool.MachinePool.Spec.Replicas; replicas != nil {
+			worker.Replicas = int(*replicas)
+		}
+
+		workers[manifests.WorkerName(values.Cluster.Name, machinePool.MachinePool, pool.Spec.EKSNodegroupName)] = worker
+	}
+
+	if len(workers) > 0 {
+		values.Workers.AWSWorkers = &workers
+	}
+
+	return values, nil
+}
+
+func readControlPlaneSpec(spec ekscontrolplanev1.AWSManagedControlPlaneSpec, path string, report *api.ConversionReport) *api.AWSCloudSpec {
+	result := &api.AWSCloudSpec{
+		Region:                spec.Region,
+		AdditionalTags:        spec.AdditionalTags,
+		ControlPlaneEndpoint:  spec.ControlPlaneEndpoint,
+		AssociateOIDCProvider: spec.AssociateOIDCProvider,
+		Bastion:               spec.Bastion,
+		IdentityRef:           spec.IdentityRef,
+		NetworkSpec:           spec.NetworkSpec,
+		KubeProxy:             api.KubeProxy{Disable: spec.KubeProxy.Disable},
+		VpcCni:                api.VpcCni{Disable: spec.VpcCni.Disable, Env: spec.VpcCni.Env},
+		EndpointAccess: api.EndpointAccess{
+			Public:  spec.EndpointAccess.Public != nil && *spec.EndpointAccess.Public,
+			Private: spec.EndpointAccess.Private != nil && *spec.EndpointAccess.Private,
+		},
+	}
+
+	if spec.SecondaryCidrBlock != nil {
+		result.SecondaryCidrBlock = *spec.SecondaryCidrBlock
+	}
+	if spec.SSHKeyName != nil {
+		result.SSHKeyName = *spec.SSHKeyName
+	}
+	if spec.RoleName != nil {
+		result.RoleName = *spec.RoleName
+	}
+	if spec.RoleAdditionalPolicies != nil {
+		result.RoleAdditionalPolicies = *spec.RoleAdditionalPolicies
+	}
+	if spec.TokenMethod != nil {
+		result.TokenMethod = api.EKSTokenMethod(*spec.TokenMethod)
+	}
+
+	for _, cidr := range spec.EndpointAccess.PublicCIDRs {
+		if cidr != nil {
+			result.EndpointAccess.PublicCIDRs = append(result.EndpointAccess.PublicCIDRs, *cidr)
+		}
+	}
+
+	if logging := spec.Logging; logging != nil {
+		result.Logging = api.ControlPlaneLoggingSpec{
+			APIServer:         logging.APIServer,
+			Audit:             logging.Audit,
+			Authenticator:     logging.Authenticator,
+			ControllerManager: logging.ControllerManager,
+			Scheduler:         logging.Scheduler,
+		}
+	}
+
+	if encryption := spec.EncryptionConfig; encryption != nil && encryption.Provider != nil {
+		result.EncryptionConfig.Provider = *encryption.Provider
+		for _, resource := range encryption.Resources {
+			if resource != nil {
+				result.EncryptionConfig.Resources = append(result.EncryptionConfig.Resources, *resource)
+			}
+		}
+	}
+
+	if config := spec.IAMAuthenticatorConfig; config != nil {
+		for _, mapping := range config.RoleMappings {
+			result.IAMAuthenticatorConfig.RoleMappings = append(result.IAMAuthenticatorConfig.RoleMappings, api.RoleMapping{
+				RoleARN:           mapping.RoleARN,
+				KubernetesMapping: api.KubernetesMapping(mapping.KubernetesMapping),
+			})
+		}
+		for _, mapping := range config.UserMappings {
+			result.IAMAuthenticatorConfig.UserMappings = append(result.IAMAuthenticatorConfig.UserMappings, api.UserMapping{
+				UserARN:           mapping.UserARN,
+				KubernetesMapping: api.KubernetesMapping(mapping.KubernetesMapping),
+			})
+		}
+	}
+
+	if oidc := spec.OIDCIdentityProviderConfig; oidc != nil {
+		result.OIDCIdentityProviderConfig = api.OIDCIdentityProviderConfig{
+			ClientID:                   oidc.ClientID,
+			GroupsClaim:                oidc.GroupsClaim,
+			GroupsPrefix:               oidc.GroupsPrefix,
+			IdentityProviderConfigName: oidc.IdentityProviderConfigName,
+			IssuerURL:                  oidc.IssuerURL,
+			RequiredClaims:             oidc.RequiredClaims,
+			UsernameClaim:              oidc.UsernameClaim,
+			UsernamePrefix:             oidc.UsernamePrefix,
+			Tags:                       oidc.Tags,
+		}
+	}
+
+	if spec.Addons != nil {
+		for _, addon := range *spec.Addons {
+			a := api.Addon{Name: addon.Name, Version: addon.Version}
+			if addon.ConflictResolution != nil {
+				a.ConflictResolution = api.AddonResolution(*addon.ConflictResolution)
+			}
+			result.Addons = append(result.Addons, a)
+		}
+	}
+
+	if len(spec.Partition) > 0 {
+		report.Dropped(api.Path(path, "partition"), "partition cannot be expressed in the values")
+	}
+	if len(spec.ImageLookupFormat) > 0 || len(spec.ImageLookupOrg) > 0 || len(spec.ImageLookupBaseOS) > 0 {
+		report.Dropped(api.Path(path, "imageLookupFormat"), "image lookup cannot be expressed in the values")
+	}
+
+	return result
+}
+
+func readMachinePoolSpec(spec expinfrav1.AWSManagedMachinePoolSpec, path string, report *api.ConversionReport) api.AWSWorkerSpec {
+	result := api.AWSWorkerSpec{
+		AvailabilityZones:      spec.AvailabilityZones,
+		AdditionalTags:         spec.AdditionalTags,
+		RoleAdditionalPolicies: spec.RoleAdditionalPolicies,
+		Labels:                 api.PointerTags(spec.Labels),
+		InstanceType:           spec.InstanceType,
+	}
+
+	for _, subnetID := range spec.SubnetIDs {
+		result.SubnetIDs = append(result.SubnetIDs, resources.Ptr(subnetID))
+	}
+
+	if spec.AMIVersion != nil {
+		result.AMIVersion = *spec.AMIVersion
+	}
+	if spec.AMIType != nil {
+		result.AMIType = api.ManagedMachineAMIType(*spec.AMIType)
+	}
+	if spec.CapacityType != nil {
+		result.CapacityType = api.ManagedMachinePoolCapacityType(*spec.CapacityType)
+	}
+	if spec.DiskSize != nil {
+		result.DiskSize = *spec.DiskSize
+	}
+	if scaling := spec.Scaling; scaling != nil && scaling.MinSize != nil && scaling.MaxSize != nil {
+		result.Scaling = &api.ManagedMachinePoolScaling{MinSize: *scaling.MinSize, MaxSize: *scaling.MaxSize}
+	}
+	if update := spec.UpdateConfig; update != nil {
+		result.UpdateConfig = &api.UpdateConfig{
+			MaxUnavailable:           update.MaxUnavailable,
+			MaxUnavailablePercentage: update.MaxUnavailablePercentage,
+		}
+	}
+
+	for _, taint := range spec.Taints {
+		result.Taints = append(result.Taints, api.Taint{
+			Effect: api.TaintEffect(taint.Effect),
+			Key:    taint.Key,
+			Value:  taint.Value,
+		})
+	}
+
+	if len(spec.RoleName) > 0 {
+		report.Dropped(api.Path(path, "roleName"), "node group role cannot be expressed in the values")
+	}
+	if spec.RemoteAccess != nil {
+		report.Dropped(api.Path(path, "remoteAccess"), "remote access cannot be expressed in the values")
+	}
+	if spec.AWSLaunchTemplate != nil {
+		report.Dropped(api.Path(path, "awsLaunchTemplate"), "launch template cannot be expressed in the values")
+	}
+
+	return result
+}
@@ -1,47 +1,123 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+
+	ekscontrolplanev1 "sigs.k8s.io/cluster-api-provider-aws/v2/controlplane/eks/api/v1beta2"
+
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 )
 
+func init() {
+	api.RegisterProvider(api.ProviderRegistration{
+		Name: api.ClusterProviderAWS,
+		NewConfiguration: func() api.Validator {
+			return &api.AWSConfiguration{}
+		},
+		NewMigrator: func(ctx context.Context, config api.Validator) (api.Migrator, error) {
+			configuration, ok := config.(*api.AWSConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected aws configuration type %T", config)
+			}
+
+			return NewAWSMigrator(ctx, configuration)
+		},
+		NewReplayMigrator: func(config api.Validator, responses []byte) (api.Migrator, error) {
+			configuration, ok := config.(*api.AWSConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected aws configuration type %T", config)
+			}
+
+			return NewAWSReplayMigrator(configuration, responses)
+		},
+		RenderManifests:  renderManifests,
+		ReadManifests:    readManifests,
+		ControlPlaneKind: ekscontrolplanev1.GroupVersion.WithKind("AWSManagedControlPlane").GroupKind(),
+	})
+}
+
 type Migrator struct {
 	accessor api.ClusterAccessor
 }
 
-func (m Migrator) AddTags(tags map[string]string) error {
-	if err := m.accessor.AddClusterTags(tags); err != nil {
-		return err
+func (m Migrator) AddTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan, err := m.PlanTags(ctx, tags)
+	if err != nil {
+		return nil, err
 	}
-	if err := m.accessor.AddMachinePollsTags(tags); err != nil {
-		return err
+	return plan, m.ApplyTags(ctx, plan)
+}
+
+func (m Migrator) PlanTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan := api.NewTagPlan(api.ClusterProviderAWS, tags)
+	if err := m.accessor.PlanClusterTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	if err := m.accessor.PlanMachinePoolsTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	if err := m.accessor.PlanVirtualNetworkTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (m Migrator) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderAWS {
+		return fmt.Errorf("cannot apply %s tag plan to aws cluster", plan.Provider)
 	}
-	if err := m.accessor.AddVirtualNetworkTags(tags); err != nil {
-		return err
+	return m.accessor.ApplyTags(ctx, plan)
+}
+
+func (m Migrator) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderAWS {
+		return fmt.Errorf("cannot remove %s tag plan from aws cluster", plan.Provider)
 	}
-	return nil
+	return m.accessor.RemoveTags(ctx, plan)
 }
 
-func (m Migrator) Convert() (*api.Values, error) {
-	c, err := m.accessor.GetCluster()
+func (m Migrator) Convert(ctx context.Context) (*api.Values, *api.ConversionReport, error) {
+	report := api.NewConversionReport()
+	c, err := m.accessor.GetCluster(ctx, report)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	w, err := m.accessor.GetWorkers()
+	w, err := m.accessor.GetWorkers(ctx, report)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := &api.Values{
+		APIVersion: api.Version,
+		Provider:   api.ClusterProviderAWS,
+		Type:       api.ClusterTypeManaged,
+		Cluster:    *c,
+		Workers:    *w,
+	}
+	values.SetDefaultWorkers(api.LatestDefaultWorkers())
+	values.Sort()
+	report.Sort()
+
+	return values, report, nil
+}
+
+func NewAWSMigrator(ctx context.Context, configuration *api.AWSConfiguration) (api.Migrator, error) {
+	a, err := (&ClusterAccessor{
+		configuration: configuration,
+	}).init(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &api.Values{
-		Provider: api.ClusterProviderAWS,
-		Type:     api.ClusterTypeManaged,
-		Cluster:  *c,
-		Workers:  *w,
+	return &Migrator{
+		accessor: a,
 	}, nil
 }
 
-func NewAWSMigrator(configuration *api.AWSConfiguration) (api.Migrator, error) {
+// NewAWSReplayMigrator creates migrator that converts responses recorded by Fixture without connecting to AWS.
+func NewAWSReplayMigrator(configuration *api.AWSConfiguration, responses []byte) (api.Migrator, error) {
 	a, err := (&ClusterAccessor{
 		configuration: configuration,
-	}).init()
+	}).initReplay(responses)
 	if err != nil {
 		return nil, err
 	}
@@ -49,3 +125,12 @@ func NewAWSMigrator(configuration *api.AWSConfiguration) (api.Migrator, error) {
 		accessor: a,
 	}, nil
 }
+
+// Fixture returns responses read by Convert, so that the conversion can be replayed.
+func (m Migrator) Fixture() (*api.Fixture, error) {
+	recorder, ok := m.accessor.(api.Recorder)
+	if !ok {
+		return nil, fmt.Errorf("accessor does not record responses")
+	}
+	return recorder.Fixture()
+}
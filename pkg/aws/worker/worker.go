@@ -4,154 +4,203 @@ import (
 	"context"
 	"fmt"
 
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	tageks "github.com/aws/aws-sdk-go-v2/service/eks"
-	"github.com/aws/aws-sdk-go/aws/session"
-	ekssdk "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
-	"github.com/weaveworks/eksctl/pkg/eks"
 	infrav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 )
 
+// EC2API is the part of the EC2 client used by Worker.
+type EC2API interface {
+	DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+}
+
+// EKSAPI is the part of the EKS client used by Worker.
+type EKSAPI interface {
+	eks.ListNodegroupsAPIClient
+	DescribeNodegroup(ctx context.Context, params *eks.DescribeNodegroupInput, optFns ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error)
+}
+
 type Worker struct {
-	configuration   *api.AWSConfiguration
-	ctx             context.Context
-	ClusterProvider *eks.ClusterProvider
+	configuration *api.AWSConfiguration
+	ec2Client     EC2API
+	eksClient     EKSAPI
 }
 
-func NewAWSWorker(ctx context.Context, configuration *api.AWSConfiguration, clusterProvider *eks.ClusterProvider) *Worker {
+// NewAWSWorker creates worker that reads node groups with the given clients. Clients can be nil if only Convert is used.
+func NewAWSWorker(configuration *api.AWSConfiguration, ec2Client EC2API, eksClient EKSAPI) *Worker {
 	return &Worker{
-		configuration:   configuration,
-		ctx:             ctx,
-		ClusterProvider: clusterProvider,
+		configuration: configuration,
+		ec2Client:     ec2Client,
+		eksClient:     eksClient,
 	}
 }
 
-func (this *Worker) GetWorkers() (*api.Workers, error) {
-	cfg, err := awsConfig.LoadDefaultConfig(this.ctx)
+// Snapshot holds raw responses of the EKS and EC2 APIs the workers are converted from.
+type Snapshot struct {
+	Nodegroups []*ekstypes.Nodegroup `json:"nodegroups"`
+	// Subnets of the node groups by ID.
+	Subnets map[string]ec2Types.Subnet `json:"subnets"`
+}
+
+func (this *Worker) GetWorkers(ctx context.Context, report *api.ConversionReport) (*api.Workers, error) {
+	snapshot, err := this.Fetch(ctx)
 	if err != nil {
 		return nil, err
 	}
-	cfg.Region = this.configuration.Region
-	svc := ec2.NewFromConfig(cfg)
-	mySession := session.Must(session.NewSession())
-	eksSvc := ekssdk.New(mySession)
 
-	ngList, err := eksSvc.ListNodegroups(&ekssdk.ListNodegroupsInput{
-		ClusterName: &this.configuration.ClusterName,
-	})
+	return this.Convert(snapshot, report)
+}
+
+// Fetch reads all node groups of the cluster together with their subnets.
+func (this *Worker) Fetch(ctx context.Context) (*Snapshot, error) {
+	nodegroups, err := this.describeNodegroups(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	workers := &api.Workers{
-		WorkersSpec: api.WorkersSpec{
-			AWSWorkers: getDefaultAwsWorkers(),
-		},
+	snapshot := &Snapshot{
+		Nodegroups: nodegroups,
+		Subnets:    map[string]ec2Types.Subnet{},
 	}
-	for _, ng := range ngList.Nodegroups {
-		nodeGroup, err := eksSvc.DescribeNodegroup(&ekssdk.DescribeNodegroupInput{
-			ClusterName:   &this.configuration.ClusterName,
-			NodegroupName: ng,
-		})
+	subnetID := "subnet-id"
+	for _, nodegroup := range nodegroups {
+		for _, subnet := range nodegroup.Subnets {
+			if _, ok := snapshot.Subnets[subnet]; ok {
+				continue
+			}
+
+			subnets, err := this.ec2Client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+				Filters: []ec2Types.Filter{
+					{Name: &subnetID, Values: []string{subnet}},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(subnets.Subnets) == 0 {
+				return nil, fmt.Errorf("couldn't find the subnet %s", subnet)
+			}
+			snapshot.Subnets[subnet] = subnets.Subnets[0]
+		}
+	}
+
+	return snapshot, nil
+}
+
+func (this *Worker) describeNodegroups(ctx context.Context) ([]*ekstypes.Nodegroup, error) {
+	result := make([]*ekstypes.Nodegroup, 0)
+	paginator := eks.NewListNodegroupsPaginator(this.eksClient, &eks.ListNodegroupsInput{
+		ClusterName: &this.configuration.ClusterName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
 		if err != nil {
 			return nil, err
 		}
-		availabilityZones := []string{}
-		subnetID := "subnet-id"
-		for _, subnet := range nodeGroup.Nodegroup.Subnets {
-			subnets, err := svc.DescribeSubnets(this.ctx, &ec2.DescribeSubnetsInput{
-				Filters: []ec2Types.Filter{
-					{Name: &subnetID, Values: []string{*subnet}},
-				},
+
+		for _, name := range page.Nodegroups {
+			nodegroup, err := this.eksClient.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+				ClusterName:   &this.configuration.ClusterName,
+				NodegroupName: &name,
 			})
 			if err != nil {
 				return nil, err
 			}
-			availabilityZones = append(availabilityZones, *subnets.Subnets[0].AvailabilityZone)
+			result = append(result, nodegroup.Nodegroup)
+		}
+	}
+
+	return result, nil
+}
+
+// Convert builds workers values from the responses without calling AWS APIs.
+func (this *Worker) Convert(snapshot *Snapshot, report *api.ConversionReport) (*api.Workers, error) {
+	workers := &api.Workers{
+		WorkersSpec: api.WorkersSpec{
+			AWSWorkers: &api.AWSWorkers{},
+		},
+	}
+	for _, nodegroup := range snapshot.Nodegroups {
+		availabilityZones := []string{}
+		subnetIDs := []*string{}
+		for _, subnet := range nodegroup.Subnets {
+			s, ok := snapshot.Subnets[subnet]
+			if !ok {
+				return nil, fmt.Errorf("subnet %s of node group %s not found in the responses", subnet, *nodegroup.NodegroupName)
+			}
+			subnetIDs = append(subnetIDs, s.SubnetId)
+			availabilityZones = append(availabilityZones, *s.AvailabilityZone)
 		}
 		newWorkers := *workers.AWSWorkers
-		newWorkers[*ng] = &api.AWSWorker{
-			Replicas:    int(*nodeGroup.Nodegroup.ScalingConfig.DesiredSize),
+		newWorkers[*nodegroup.NodegroupName] = &api.AWSWorker{
+			Replicas:    int(*nodegroup.ScalingConfig.DesiredSize),
 			Labels:      nil,
 			Annotations: nil,
 			IsMultiAZ:   true, // default to true so that the availability zones we discovered are used
 			Spec: api.AWSWorkerSpec{
-				Labels:       nodeGroup.Nodegroup.Labels,
+				Labels:       pointerLabels(nodegroup.Labels),
 				AMIVersion:   "", //amiVersion.Version,
-				AMIType:      api.ManagedMachineAMIType(*nodeGroup.Nodegroup.AmiType),
-				DiskSize:     int32(*nodeGroup.Nodegroup.DiskSize),
-				InstanceType: nodeGroup.Nodegroup.InstanceTypes[0],
+				AMIType:      api.ManagedMachineAMIType(nodegroup.AmiType),
+				DiskSize:     int32(*nodegroup.DiskSize),
+				InstanceType: &nodegroup.InstanceTypes[0],
 				Scaling: &api.ManagedMachinePoolScaling{
-					MinSize: int32(*nodeGroup.Nodegroup.ScalingConfig.MinSize),
-					MaxSize: int32(*nodeGroup.Nodegroup.ScalingConfig.MaxSize),
+					MinSize: int32(*nodegroup.ScalingConfig.MinSize),
+					MaxSize: int32(*nodegroup.ScalingConfig.MaxSize),
 				},
 				AvailabilityZones: availabilityZones,
-				SubnetIDs:         nodeGroup.Nodegroup.Subnets,
-				Taints: func(taints []*ekssdk.Taint) api.Taints {
+				SubnetIDs:         subnetIDs,
+				Taints: func(taints []ekstypes.Taint) api.Taints {
 					newTaints := api.Taints{}
 					for _, taint := range taints {
 						newTaints = append(newTaints, api.Taint{
-							Effect: taintEffect(*taint.Effect),
+							Effect: taintEffect(string(taint.Effect)),
 							Key:    *taint.Key,
 							Value:  *taint.Value,
 						})
 					}
 					return newTaints
-				}(nodeGroup.Nodegroup.Taints),
+				}(nodegroup.Taints),
 				UpdateConfig: nil,
-				AdditionalTags: func(tags map[string]*string) infrav1.Tags {
+				AdditionalTags: func(tags map[string]string) infrav1.Tags {
 					newTags := infrav1.Tags{fmt.Sprintf("kubernetes.io/cluster/%s", this.configuration.ClusterName): "owned"}
 					for key, value := range tags {
-						newTags[key] = *value
+						newTags[key] = value
 					}
 					return newTags
-				}(nodeGroup.Nodegroup.Tags),
+				}(nodegroup.Tags),
 			},
 		}
+		reportWorker(report, *nodegroup.NodegroupName, nodegroup)
 	}
 
 	return workers, nil
 }
 
-func (this *Worker) AddMachinePollsTags(tags map[string]string) error {
-	mySession := session.Must(session.NewSession())
-	eksSvc := ekssdk.New(mySession)
-	ngList, err := eksSvc.ListNodegroups(&ekssdk.ListNodegroupsInput{
-		ClusterName: &this.configuration.ClusterName,
-	})
+const resourceKindNodegroup = "eks-nodegroup"
+
+func (this *Worker) PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	nodegroups, err := this.describeNodegroups(ctx)
 	if err != nil {
 		return err
 	}
-	for _, ng := range ngList.Nodegroups {
-		nodeGroup, err := eksSvc.DescribeNodegroup(&ekssdk.DescribeNodegroupInput{
-			ClusterName:   &this.configuration.ClusterName,
-			NodegroupName: ng,
-		})
-		if err != nil {
-			return err
-		}
-		_, err = this.ClusterProvider.AWSProvider.EKS().TagResource(this.ctx, &tageks.TagResourceInput{
-			ResourceArn: nodeGroup.Nodegroup.NodegroupArn,
-			Tags:        tags,
-		})
-		if err != nil {
-			return err
-		}
-
+	for _, nodegroup := range nodegroups {
+		plan.Add(resourceKindNodegroup, *nodegroup.NodegroupArn, nodegroup.Tags, tags)
 	}
 
 	return nil
 }
 
-func getDefaultAwsWorkers() *api.AWSWorkers {
-	return &api.AWSWorkers{
-		"small-burst-on-demand":  nil,
-		"medium-burst-on-demand": nil,
-		"large-burst-on-demand":  nil,
+func pointerLabels(labels map[string]string) map[string]*string {
+	result := map[string]*string{}
+	for key, value := range labels {
+		value := value
+		result[key] = &value
 	}
+
+	return result
 }
 
 func taintEffect(t string) api.TaintEffect {
@@ -162,3 +211,28 @@ func taintEffect(t string) api.TaintEffect {
 	}
 	return api.TaintEffectPreferNoSchedule
 }
+
+func workerPath(name string, elements ...string) string {
+	return api.Path(append([]string{"workers", "aws", name}, elements...)...)
+}
+
+// reportWorker records values of converted worker that were not read from the EKS node group.
+func reportWorker(report *api.ConversionReport, name string, nodeGroup *ekstypes.Nodegroup) {
+	report.Defaulted(workerPath(name, "isMultiAZ"), "node group is always spread across discovered availability zones")
+
+	if nodeGroup.ReleaseVersion != nil {
+		report.Dropped(workerPath(name, "spec", "amiVersion"), "AMI release version %s is not converted, latest version will be used", *nodeGroup.ReleaseVersion)
+	}
+
+	if len(nodeGroup.CapacityType) > 0 && nodeGroup.CapacityType != ekstypes.CapacityTypesOnDemand {
+		report.Dropped(workerPath(name, "spec", "capacityType"), "capacity type %s is not converted, on-demand will be used", nodeGroup.CapacityType)
+	}
+
+	if len(nodeGroup.InstanceTypes) > 1 {
+		report.Approximated(workerPath(name, "spec", "instanceType"), "only the first of %d instance types is used", len(nodeGroup.InstanceTypes))
+	}
+
+	if nodeGroup.UpdateConfig != nil {
+		report.Dropped(workerPath(name, "spec", "updateConfig"), "node group update configuration is not converted")
+	}
+}
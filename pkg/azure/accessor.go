@@ -1,11 +1,18 @@
 package azure
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2022-03-01/containerservice"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 	"github.com/pluralsh/cluster-api-migration/pkg/azure/cluster"
@@ -15,59 +22,187 @@ import (
 
 type ClusterAccessor struct {
 	configuration         *api.AzureConfiguration
-	ctx                   context.Context
 	managedClustersClient containerservice.ManagedClustersClient
 	virtualNetworksClient *armnetwork.VirtualNetworksClient
+	managedClustersBody   *bodyRecorder
+	// snapshot holds responses read by GetCluster and GetWorkers, or responses to replay.
+	snapshot *Snapshot
+	replay   bool
 }
 
-func (accessor *ClusterAccessor) AddClusterTags(tags map[string]string) error {
-	params := containerservice.TagsObject{Tags: map[string]*string{}}
-	for key, value := range tags {
-		params.Tags[key] = resources.Ptr(value)
+// Snapshot holds raw responses the cluster and workers are converted from.
+type Snapshot struct {
+	// ManagedCluster is stored as returned by Azure, as MarshalJSON of the model drops read-only fields.
+	ManagedCluster json.RawMessage            `json:"managedCluster"`
+	VirtualNetwork *armnetwork.VirtualNetwork `json:"virtualNetwork"`
+}
+
+// bodyRecorder is an autorest sender that keeps the body of the last response.
+type bodyRecorder struct {
+	sender autorest.Sender
+	body   []byte
+}
+
+func (r *bodyRecorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.sender.Do(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := accessor.managedClustersClient.UpdateTags(accessor.ctx, accessor.configuration.ResourceGroup, accessor.configuration.Name, params)
-	return err
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	r.body = body
+	return resp, nil
+}
+
+const (
+	resourceKindManagedCluster = "aks-cluster"
+	resourceKindVirtualNetwork = "virtual-network"
+)
+
+func (accessor *ClusterAccessor) PlanClusterTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	if accessor.replay {
+		return api.ErrReplay
+	}
+
+	c, err := accessor.getManagedCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	plan.Add(resourceKindManagedCluster, *c.ID, api.StringTags(c.Tags), tags)
+	return nil
 }
 
-func (accessor *ClusterAccessor) AddMachinePollsTags(Tags map[string]string) error {
+func (accessor *ClusterAccessor) PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (accessor *ClusterAccessor) AddVirtualNetworkTags(tags map[string]string) error {
-	c, err := accessor.managedClustersClient.Get(accessor.ctx, accessor.configuration.ResourceGroup, accessor.configuration.Name)
+func (accessor *ClusterAccessor) PlanVirtualNetworkTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	if accessor.replay {
+		return api.ErrReplay
+	}
+
+	c, err := accessor.getManagedCluster(ctx)
 	if err != nil {
 		return err
 	}
 
 	vnet, _ := cluster.VirtualNetworkSubnetNames(&c)
+	v, err := accessor.virtualNetworksClient.Get(ctx, accessor.configuration.ResourceGroup, vnet, nil)
+	if err != nil {
+		return wrapError(virtualNetworkResource(vnet), err)
+	}
+
+	plan.Add(resourceKindVirtualNetwork, *v.ID, api.StringTags(v.Tags), tags)
+	return nil
+}
+
+// ApplyTags writes planned tags together with the tags the resources already have,
+// as tags sent to UpdateTags replace all existing ones.
+func (accessor *ClusterAccessor) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	if accessor.replay {
+		return api.ErrReplay
+	}
+
+	return plan.Apply(func(resource api.ResourceTagPlan) error {
+		return accessor.updateTags(ctx, resource, resource.Tags(), nil)
+	})
+}
+
+func (accessor *ClusterAccessor) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	if accessor.replay {
+		return api.ErrReplay
+	}
+
+	return plan.Revert(func(resource api.ResourceTagPlan) error {
+		return accessor.updateTags(ctx, resource, resource.RestoredTags(), resource.RemovedKeys())
+	})
+}
+
+// updateTags sets and removes tags of the resource, keeping all other tags it has.
+func (accessor *ClusterAccessor) updateTags(ctx context.Context, resource api.ResourceTagPlan, set map[string]string, remove []string) error {
+	switch resource.Kind {
+	case resourceKindManagedCluster:
+		c, err := accessor.getManagedCluster(ctx)
+		if err != nil {
+			return err
+		}
+
+		params := containerservice.TagsObject{Tags: mergeTags(c.Tags, set, remove)}
+		_, err = accessor.managedClustersClient.UpdateTags(ctx, accessor.configuration.ResourceGroup, accessor.configuration.Name, params)
+		return wrapError(accessor.managedClusterResource(), err)
+	case resourceKindVirtualNetwork:
+		vnet := path.Base(resource.ID)
+		v, err := accessor.virtualNetworksClient.Get(ctx, accessor.configuration.ResourceGroup, vnet, nil)
+		if err != nil {
+			return wrapError(virtualNetworkResource(vnet), err)
+		}
+
+		params := armnetwork.TagsObject{Tags: mergeTags(v.Tags, set, remove)}
+		_, err = accessor.virtualNetworksClient.UpdateTags(ctx, accessor.configuration.ResourceGroup, vnet, params, nil)
+		return wrapError(virtualNetworkResource(vnet), err)
+	}
 
-	params := armnetwork.TagsObject{Tags: map[string]*string{}}
-	for key, value := range tags {
-		params.Tags[key] = resources.Ptr(value)
+	return fmt.Errorf("unsupported resource kind %q", resource.Kind)
+}
+
+func mergeTags(existing map[string]*string, set map[string]string, remove []string) map[string]*string {
+	result := map[string]*string{}
+	for key, value := range existing {
+		result[key] = value
+	}
+	for key, value := range set {
+		result[key] = resources.Ptr(value)
+	}
+	for _, key := range remove {
+		delete(result, key)
 	}
 
-	_, err = accessor.virtualNetworksClient.UpdateTags(accessor.ctx, accessor.configuration.ResourceGroup, vnet, params, nil)
-	return err
+	return result
 }
 
 func (accessor *ClusterAccessor) init() (api.ClusterAccessor, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	httpClient, err := accessor.httpClient()
 	if err != nil {
 		return nil, err
 	}
 
-	accessor.managedClustersClient = containerservice.NewManagedClustersClient(accessor.configuration.SubscriptionID)
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		ClientOptions: accessor.clientOptions(httpClient),
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	accessor.managedClustersClient = containerservice.NewManagedClustersClientWithBaseURI(
+		accessor.resourceManagerEndpoint(),
+		accessor.configuration.SubscriptionID,
+	)
+
 	accessor.managedClustersClient.Authorizer, err = auth.NewAuthorizerFromCLI()
 	if err != nil {
 		return nil, err
 	}
 
-	accessor.virtualNetworksClient, err = armnetwork.NewVirtualNetworksClient(accessor.configuration.SubscriptionID, cred, nil)
+	var sender autorest.Sender = autorest.CreateSender()
+	if httpClient != nil {
+		sender = httpClient
+	}
+	accessor.managedClustersBody = &bodyRecorder{sender: sender}
+	accessor.managedClustersClient.Sender = accessor.managedClustersBody
+	accessor.snapshot = &Snapshot{}
+
+	accessor.virtualNetworksClient, err = armnetwork.NewVirtualNetworksClient(
+		accessor.configuration.SubscriptionID,
+		cred,
+		accessor.armClientOptions(httpClient),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -75,14 +210,29 @@ func (accessor *ClusterAccessor) init() (api.ClusterAccessor, error) {
 	return accessor, nil
 }
 
-func (accessor *ClusterAccessor) GetCluster() (*api.Cluster, error) {
-	c, err := accessor.managedClustersClient.Get(accessor.ctx, accessor.configuration.ResourceGroup, accessor.configuration.Name)
+// initReplay makes the accessor convert recorded responses without connecting to Azure.
+func (accessor *ClusterAccessor) initReplay(responses []byte) (api.ClusterAccessor, error) {
+	accessor.snapshot = &Snapshot{}
+	if err := json.Unmarshal(responses, accessor.snapshot); err != nil {
+		return nil, fmt.Errorf("invalid Azure responses: %w", err)
+	}
+
+	accessor.replay = true
+	return accessor, nil
+}
+
+func (accessor *ClusterAccessor) Fixture() (*api.Fixture, error) {
+	return api.NewFixture(api.ClusterProviderAzure, accessor.configuration, accessor.snapshot)
+}
+
+func (accessor *ClusterAccessor) GetCluster(ctx context.Context, report *api.ConversionReport) (*api.Cluster, error) {
+	c, err := accessor.readManagedCluster(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	vnet, _ := cluster.VirtualNetworkSubnetNames(&c)
-	v, err := accessor.virtualNetworksClient.Get(accessor.ctx, accessor.configuration.ResourceGroup, vnet, nil)
+	v, err := accessor.readVirtualNetwork(ctx, vnet)
 	if err != nil {
 		return nil, err
 	}
@@ -91,17 +241,70 @@ func (accessor *ClusterAccessor) GetCluster() (*api.Cluster, error) {
 		accessor.configuration.SubscriptionID,
 		accessor.configuration.ResourceGroup,
 		&c,
-		&v.VirtualNetwork)
-	return azureCluster.Convert()
+		v)
+	return azureCluster.Convert(report)
 }
 
 // TODO: Avoid connecting Azure API twice.
-func (accessor *ClusterAccessor) GetWorkers() (*api.Workers, error) {
-	c, err := accessor.managedClustersClient.Get(accessor.ctx, accessor.configuration.ResourceGroup, accessor.configuration.Name)
+func (accessor *ClusterAccessor) GetWorkers(ctx context.Context, report *api.ConversionReport) (*api.Workers, error) {
+	c, err := accessor.readManagedCluster(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	azureWorkers := worker.NewAzureWorkers(accessor.configuration.SubscriptionID, accessor.configuration.ResourceGroup, &c)
-	return azureWorkers.Convert()
+	return azureWorkers.Convert(report)
+}
+
+// readManagedCluster reads the AKS cluster from Azure and records the response, or reads it from the recorded responses.
+func (accessor *ClusterAccessor) readManagedCluster(ctx context.Context) (containerservice.ManagedCluster, error) {
+	c := containerservice.ManagedCluster{}
+	if accessor.replay {
+		if accessor.snapshot.ManagedCluster == nil {
+			return c, fmt.Errorf("%s: responses were not recorded", accessor.managedClusterResource())
+		}
+
+		err := json.Unmarshal(accessor.snapshot.ManagedCluster, &c)
+		return c, err
+	}
+
+	c, err := accessor.getManagedCluster(ctx)
+	if err != nil {
+		return c, err
+	}
+
+	accessor.snapshot.ManagedCluster = accessor.managedClustersBody.body
+	return c, nil
+}
+
+// readVirtualNetwork reads the virtual network from Azure and records the response, or reads it from the recorded responses.
+func (accessor *ClusterAccessor) readVirtualNetwork(ctx context.Context, name string) (*armnetwork.VirtualNetwork, error) {
+	if accessor.replay {
+		if accessor.snapshot.VirtualNetwork == nil {
+			return nil, fmt.Errorf("%s: responses were not recorded", virtualNetworkResource(name))
+		}
+
+		return accessor.snapshot.VirtualNetwork, nil
+	}
+
+	v, err := accessor.virtualNetworksClient.Get(ctx, accessor.configuration.ResourceGroup, name, nil)
+	if err != nil {
+		return nil, wrapError(virtualNetworkResource(name), err)
+	}
+
+	accessor.snapshot.VirtualNetwork = &v.VirtualNetwork
+	return &v.VirtualNetwork, nil
+}
+
+func (accessor *ClusterAccessor) getManagedCluster(ctx context.Context) (containerservice.ManagedCluster, error) {
+	c, err := accessor.managedClustersClient.Get(ctx, accessor.configuration.ResourceGroup, accessor.configuration.Name)
+	return c, wrapError(accessor.managedClusterResource(), err)
+}
+
+func (accessor *ClusterAccessor) managedClusterResource() string {
+	return fmt.Sprintf("AKS cluster %s in resource group %s", accessor.configuration.Name, accessor.configuration.ResourceGroup)
+}
+
+func virtualNetworkResource(name string) string {
+	return fmt.Sprintf("virtual network %s", name)
 }
@@ -0,0 +1,56 @@
+package azure
+
+import (
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2022-03-01/containerservice"
+)
+
+// httpClient returns client that uses proxy and CA bundle from the configuration. It returns nil
+// if they are not set, so that the SDK defaults are kept.
+func (accessor *ClusterAccessor) httpClient() (*http.Client, error) {
+	if accessor.configuration.ClientOptions.IsZero() {
+		return nil, nil
+	}
+
+	return accessor.configuration.HTTPClient()
+}
+
+// clientOptions returns options of the credential and ARM clients.
+func (accessor *ClusterAccessor) clientOptions(httpClient *http.Client) policy.ClientOptions {
+	options := policy.ClientOptions{}
+	if httpClient != nil {
+		options.Transport = httpClient
+	}
+
+	// Token audience stays the one of the public cloud, only requests are sent elsewhere.
+	if endpoint := accessor.configuration.Endpoints.ResourceManager; len(endpoint) > 0 {
+		options.Cloud = cloud.Configuration{
+			ActiveDirectoryAuthorityHost: cloud.AzurePublic.ActiveDirectoryAuthorityHost,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {
+					Endpoint: endpoint,
+					Audience: cloud.AzurePublic.Services[cloud.ResourceManager].Audience,
+				},
+			},
+		}
+	}
+
+	return options
+}
+
+func (accessor *ClusterAccessor) armClientOptions(httpClient *http.Client) *arm.ClientOptions {
+	return &arm.ClientOptions{ClientOptions: accessor.clientOptions(httpClient)}
+}
+
+// resourceManagerEndpoint returns base URI of the autorest clients.
+func (accessor *ClusterAccessor) resourceManagerEndpoint() string {
+	if endpoint := accessor.configuration.Endpoints.ResourceManager; len(endpoint) > 0 {
+		return endpoint
+	}
+
+	return containerservice.DefaultBaseURI
+}
@@ -31,12 +31,14 @@ func (cluster *Cluster) SSHPublicKey() *string {
 	return resources.Ptr("skip")
 }
 
-func (cluster *Cluster) Convert() (*api.Cluster, error) {
+func (cluster *Cluster) Convert(report *api.ConversionReport) (*api.Cluster, error) {
+	cluster.Report(report)
+
 	return &api.Cluster{
 		Name:              *cluster.Cluster.Name,
 		PodCIDRBlocks:     cluster.PodCIDRBlocks(),
 		ServiceCIDRBlocks: cluster.ServiceCIDRBlocks(),
-		KubernetesVersion: *cluster.Cluster.KubernetesVersion,
+		KubernetesVersion: api.KubernetesVersion(*cluster.Cluster.KubernetesVersion),
 		CloudSpec: api.CloudSpec{
 			AzureCloudSpec: &api.AzureCloudSpec{
 				// Omitted client ID and secret as it will be filled by values.yaml.tpl.
@@ -1,6 +1,10 @@
 package cluster
 
-import "github.com/pluralsh/cluster-api-migration/pkg/api"
+import (
+	"sort"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
 
 func (cluster *Cluster) AutoscalerProfile() *api.AutoScalerProfile {
 	ap := cluster.Cluster.AutoScalerProfile
@@ -57,6 +61,9 @@ func (cluster *Cluster) AddonProfiles() []api.AddonProfile {
 			Enabled: *value.Enabled,
 		})
 	}
+	sort.Slice(addonProfiles, func(i, j int) bool {
+		return addonProfiles[i].Name < addonProfiles[j].Name
+	})
 
 	return addonProfiles
 }
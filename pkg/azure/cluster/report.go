@@ -0,0 +1,28 @@
+package cluster
+
+import "github.com/pluralsh/cluster-api-migration/pkg/api"
+
+func clusterPath(elements ...string) string {
+	return api.Path(append([]string{"cluster", "azure"}, elements...)...)
+}
+
+// Report records values of converted cluster that were not read from the AKS cluster.
+func (cluster *Cluster) Report(report *api.ConversionReport) {
+	report.Defaulted(clusterPath("clusterIdentityName"), "cluster identity name is always set to cluster-identity")
+	report.Defaulted(clusterPath("clusterIdentityType"), "cluster identity type is always set to ServicePrincipal")
+	report.Defaulted(clusterPath("clientSecretName"), "client secret name is always set to cluster-identity-secret")
+	report.Defaulted(clusterPath("allowedNamespaces"), "identity can be used from all namespaces")
+
+	if *cluster.SSHPublicKey() == "skip" {
+		report.Defaulted(clusterPath("sshPublicKey"), "cluster has no SSH public key, placeholder value skip is used")
+	}
+
+	if cluster.Cluster.AadProfile != nil {
+		report.Dropped(clusterPath("aadProfile"), "Azure Active Directory profile is not converted")
+	}
+
+	if len(cluster.VNet.Properties.Subnets) > 1 {
+		report.Approximated(clusterPath("virtualNetwork", "subnet", "cidrBlock"),
+			"CIDR block of the first of %d virtual network subnets is used", len(cluster.VNet.Properties.Subnets))
+	}
+}
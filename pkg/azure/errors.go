@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/go-autorest/autorest"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// wrapError converts errors returned by ARM clients into typed API errors.
+func wrapError(resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return api.NewError(errorClass(err), resource, err)
+}
+
+func errorClass(err error) api.ErrorClass {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		return statusCodeClass(responseErr.StatusCode)
+	}
+
+	var detailedErr autorest.DetailedError
+	if errors.As(err, &detailedErr) {
+		if statusCode, ok := detailedErr.StatusCode.(int); ok {
+			return statusCodeClass(statusCode)
+		}
+	}
+
+	return api.ErrorClassUnknown
+}
+
+func statusCodeClass(statusCode int) api.ErrorClass {
+	switch statusCode {
+	case http.StatusNotFound:
+		return api.ErrorClassNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return api.ErrorClassPermissionDenied
+	case http.StatusTooManyRequests:
+		return api.ErrorClassThrottled
+	}
+
+	return api.ErrorClassUnknown
+}
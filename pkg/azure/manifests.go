@@ -0,0 +1,250 @@
+package azure
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+)
+
+// CAPZ API types are not vendored, objects are rendered as unstructured ones following the v1beta1 schema.
+var (
+	infrastructureGroupVersion = schema.GroupVersion{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1"}
+	azureManagedCluster        = infrastructureGroupVersion.WithKind("AzureManagedCluster")
+	azureManagedControlPlane   = infrastructureGroupVersion.WithKind("AzureManagedControlPlane")
+	azureManagedMachinePool    = infrastructureGroupVersion.WithKind("AzureManagedMachinePool")
+	azureClusterIdentity       = infrastructureGroupVersion.WithKind("AzureClusterIdentity")
+)
+
+// clusterClass describes templates of the rendered objects, CAPZ provides them for all the managed kinds.
+// Fields that identify the cluster in Azure are cluster variables, so that the class can be shared.
+var clusterClass = &api.ClusterClassSupport{
+	ClusterVariables: []string{
+		"subscriptionID",
+		"location",
+		"resourceGroupName",
+		"nodeResourceGroupName",
+		"identityRef",
+		"virtualNetwork",
+		"sshPublicKey",
+	},
+	PoolVariables: []string{"name"},
+	BootstrapTemplate: func(name string) runtime.Object {
+		return &bootstrapv1.KubeadmConfigTemplate{
+			TypeMeta:   manifests.TypeMeta(bootstrapv1.GroupVersion.WithKind("KubeadmConfigTemplate")),
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+	},
+}
+
+// renderManifests returns Cluster, AzureManagedCluster, AzureManagedControlPlane and a MachinePool
+// with AzureManagedMachinePool for every worker. AKS uses name of the control plane as the cluster name
+// and the agent pool name is set explicitly, so that CAPZ adopts the existing ones.
+func renderManifests(values *api.Values, report *api.ConversionReport) ([]runtime.Object, error) {
+	spec := values.Cluster.AzureCloudSpec
+	if spec == nil {
+		return nil, fmt.Errorf("values do not contain azure cluster")
+	}
+
+	name := values.Cluster.Name
+	controlPlaneSpec, err := controlPlaneSpec(values.Cluster.KubernetesVersion, spec, report)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := manifests.NewCluster(
+		values.Cluster,
+		nil,
+		manifests.Reference(azureManagedControlPlane, name),
+		manifests.Reference(azureManagedCluster, name),
+	)
+
+	result := []runtime.Object{
+		cluster,
+		manifests.NewObject(azureManagedCluster, name, nil),
+		manifests.NewObject(azureManagedControlPlane, name, controlPlaneSpec),
+	}
+	if values.Workers.AzureWorkers == nil {
+		return result, nil
+	}
+
+	workers := *values.Workers.AzureWorkers
+	names := make([]string, 0, len(workers))
+	for workerName := range workers {
+		names = append(names, workerName)
+	}
+	sort.Strings(names)
+
+	for _, workerName := range names {
+		worker := workers[workerName]
+		if worker == nil {
+			continue
+		}
+
+		poolSpec, err := manifests.ToMap(worker.Spec)
+		if err != nil {
+			return nil, err
+		}
+		poolSpec["name"] = workerName
+
+		version := values.Cluster.KubernetesVersion
+		versionPath := "cluster.kubernetesVersion"
+		if worker.KubernetesVersion != nil {
+			version = *worker.KubernetesVersion
+			versionPath = api.Path("workers", "azure", workerName, "kubernetesVersion")
+		}
+
+		poolName := fmt.Sprintf("%s-%s", name, workerName)
+		machinePool := manifests.NewMachinePool(
+			name,
+			poolName,
+			resources.Ptr(int32(worker.Replicas)),
+			manifests.MachinePoolVersion(version, versionPath, report),
+			worker.Labels,
+			worker.Annotations,
+			manifests.Reference(azureManagedMachinePool, poolName),
+		)
+
+		result = append(result, machinePool, manifests.NewObject(azureManagedMachinePool, poolName, poolSpec))
+	}
+
+	return result, nil
+}
+
+func controlPlaneSpec(version string, spec *api.AzureCloudSpec, report *api.ConversionReport) (map[string]interface{}, error) {
+	result := map[string]interface{}{
+		"version":           api.KubernetesVersion(version),
+		"subscriptionID":    spec.SubscriptionID,
+		"location":          spec.Location,
+		"resourceGroupName": spec.ResourceGroupName,
+	}
+	if len(spec.NodeResourceGroupName) > 0 {
+		result["nodeResourceGroupName"] = spec.NodeResourceGroupName
+	}
+
+	if len(spec.ClusterIdentityName) > 0 {
+		result["identityRef"] = map[string]interface{}{
+			"apiVersion": azureClusterIdentity.GroupVersion().String(),
+			"kind":       azureClusterIdentity.Kind,
+			"name":       spec.ClusterIdentityName,
+		}
+		reportIdentity(spec, report)
+	} else {
+		report.Defaulted("cluster.azure.clusterIdentityName", "identity is not set, identityRef of AzureManagedControlPlane has to be added")
+	}
+
+	optional := map[string]interface{}{
+		"virtualNetwork":         spec.VirtualNetwork,
+		"networkPlugin":          spec.NetworkPlugin,
+		"networkPolicy":          spec.NetworkPolicy,
+		"outboundType":           spec.OutboundType,
+		"dnsServiceIP":           spec.DNSServiceIP,
+		"sku":                    spec.SKU,
+		"loadBalancerSKU":        spec.LoadBalancerSKU,
+		"sshPublicKey":           spec.SSHPublicKey,
+		"loadBalancerProfile":    spec.LoadBalancerProfile,
+		"apiServerAccessProfile": spec.APIServerAccessProfile,
+		"autoscalerProfile":      spec.AutoScalerProfile,
+		"aadProfile":             spec.AADProfile,
+		"addonProfiles":          spec.AddonProfiles,
+	}
+	values, err := manifests.ToMap(optional)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range values {
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// reportIdentity records settings of the identity that are not rendered, as AzureClusterIdentity referenced by
+// the control plane holds them.
+func reportIdentity(spec *api.AzureCloudSpec, report *api.ConversionReport) {
+	fields := []struct {
+		name string
+		set  bool
+	}{
+		{"clusterIdentityType", len(spec.ClusterIdentityType) > 0},
+		{"tenantID", len(spec.TenantID) > 0},
+		{"clientID", len(spec.ClientID) > 0},
+		{"clientSecretName", len(spec.ClientSecretName) > 0},
+		{"allowedNamespaces", spec.AllowedNamespaces != nil},
+	}
+	for _, field := range fields {
+		if field.set {
+			report.Dropped(api.Path("cluster", "azure", field.name), "identity is not rendered, AzureClusterIdentity %s has to exist with this setting", spec.ClusterIdentityName)
+		}
+	}
+}
+
+// readManifests rebuilds values from CAPZ objects, the reverse of renderManifests. AzureClusterIdentity is not read,
+// so only the identity name is known.
+func readManifests(objects *api.ClusterObjects, report *api.ConversionReport) (*api.Values, error) {
+	controlPlane := objects.ControlPlane
+	spec, _, err := unstructured.NestedMap(controlPlane.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+
+	version, _, _ := unstructured.NestedString(spec, "version")
+	identityName, _, _ := unstructured.NestedString(spec, "identityRef", "name")
+	for _, field := range []string{"version", "identityRef", "controlPlaneEndpoint"} {
+		delete(spec, field)
+	}
+
+	cloudSpec := &api.AzureCloudSpec{ClusterIdentityName: identityName}
+	if err = manifests.FromMap(spec, cloudSpec, manifests.ObjectPath(controlPlane, "spec"), report); err != nil {
+		return nil, err
+	}
+	if len(identityName) > 0 {
+		report.Defaulted("cluster.azure.clusterIdentityType", "identity type is set on AzureClusterIdentity %s, which is not read", identityName)
+	}
+
+	values := &api.Values{Cluster: manifests.ReadCluster(objects.Cluster)}
+	values.Cluster.KubernetesVersion = api.KubernetesVersion(version)
+	values.Cluster.AzureCloudSpec = cloudSpec
+
+	workers := api.AzureWorkers{}
+	for _, machinePool := range objects.MachinePools {
+		pool, infrastructure := machinePool.MachinePool, machinePool.Infrastructure
+		poolSpec, _, err := unstructured.NestedMap(infrastructure.Object, "spec")
+		if err != nil {
+			return nil, err
+		}
+
+		nodePoolName, _, _ := unstructured.NestedString(poolSpec, "name")
+		for _, field := range []string{"name", "providerIDList"} {
+			delete(poolSpec, field)
+		}
+
+		worker := &api.AzureWorker{KubernetesVersion: manifests.WorkerVersion(version, pool)}
+		if pool.Spec.Replicas != nil {
+			worker.Replicas = int(*pool.Spec.Replicas)
+		}
+		worker.Labels, worker.Annotations = manifests.WorkerMetadata(pool)
+
+		path := manifests.ObjectPath(infrastructure, "spec")
+		if err = manifests.FromMap(poolSpec, &worker.Spec, path, report); err != nil {
+			return nil, err
+		}
+
+		workers[manifests.WorkerName(values.Cluster.Name, pool, nodePoolName)] = worker
+	}
+
+	if len(workers) > 0 {
+		values.Workers.AzureWorkers = &workers
+	}
+
+	return values, nil
+}
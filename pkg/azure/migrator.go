@@ -2,51 +2,125 @@ package azure
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 )
 
+func init() {
+	api.RegisterProvider(api.ProviderRegistration{
+		Name: api.ClusterProviderAzure,
+		NewConfiguration: func() api.Validator {
+			return &api.AzureConfiguration{}
+		},
+		NewMigrator: func(ctx context.Context, config api.Validator) (api.Migrator, error) {
+			configuration, ok := config.(*api.AzureConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected azure configuration type %T", config)
+			}
+
+			return NewAzureMigrator(ctx, configuration)
+		},
+		NewReplayMigrator: func(config api.Validator, responses []byte) (api.Migrator, error) {
+			configuration, ok := config.(*api.AzureConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected azure configuration type %T", config)
+			}
+
+			return NewAzureReplayMigrator(configuration, responses)
+		},
+		RenderManifests:  renderManifests,
+		ClusterClass:     clusterClass,
+		ReadManifests:    readManifests,
+		ControlPlaneKind: azureManagedControlPlane.GroupKind(),
+	})
+}
+
 type Migrator struct {
 	accessor api.ClusterAccessor
 }
 
-func (migrator *Migrator) AddTags(tags map[string]string) error {
-	if err := migrator.accessor.AddClusterTags(tags); err != nil {
-		return err
+func (migrator *Migrator) AddTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan, err := migrator.PlanTags(ctx, tags)
+	if err != nil {
+		return nil, err
 	}
-	if err := migrator.accessor.AddMachinePollsTags(tags); err != nil {
-		return err
+	return plan, migrator.ApplyTags(ctx, plan)
+}
+
+func (migrator *Migrator) PlanTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan := api.NewTagPlan(api.ClusterProviderAzure, tags)
+	if err := migrator.accessor.PlanClusterTags(ctx, tags, plan); err != nil {
+		return nil, err
 	}
-	if err := migrator.accessor.AddVirtualNetworkTags(tags); err != nil {
-		return err
+	if err := migrator.accessor.PlanMachinePoolsTags(ctx, tags, plan); err != nil {
+		return nil, err
 	}
-	return nil
+	if err := migrator.accessor.PlanVirtualNetworkTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (migrator *Migrator) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderAzure {
+		return fmt.Errorf("cannot apply %s tag plan to azure cluster", plan.Provider)
+	}
+	return migrator.accessor.ApplyTags(ctx, plan)
 }
 
-func (migrator *Migrator) Convert() (*api.Values, error) {
-	c, err := migrator.accessor.GetCluster()
+func (migrator *Migrator) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderAzure {
+		return fmt.Errorf("cannot remove %s tag plan from azure cluster", plan.Provider)
+	}
+	return migrator.accessor.RemoveTags(ctx, plan)
+}
+
+func (migrator *Migrator) Convert(ctx context.Context) (*api.Values, *api.ConversionReport, error) {
+	report := api.NewConversionReport()
+
+	c, err := migrator.accessor.GetCluster(ctx, report)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	w, err := migrator.accessor.GetWorkers()
+	w, err := migrator.accessor.GetWorkers(ctx, report)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := &api.Values{
+		APIVersion: api.Version,
+		Provider:   api.ClusterProviderAzure,
+		Type:       api.ClusterTypeManaged,
+		Cluster:    *c,
+		Workers:    *w,
+	}
+	values.SetDefaultWorkers(api.LatestDefaultWorkers())
+	values.Sort()
+	report.Sort()
+
+	return values, report, nil
+}
+
+func NewAzureMigrator(ctx context.Context, configuration *api.AzureConfiguration) (api.Migrator, error) {
+	a, err := (&ClusterAccessor{
+		configuration: configuration,
+	}).init()
 	if err != nil {
 		return nil, err
 	}
 
-	return &api.Values{
-		Provider: api.ClusterProviderAzure,
-		Type:     api.ClusterTypeManaged,
-		Cluster:  *c,
-		Workers:  *w,
+	return &Migrator{
+		accessor: a,
 	}, nil
 }
 
-func NewAzureMigrator(configuration *api.AzureConfiguration) (api.Migrator, error) {
+// NewAzureReplayMigrator creates migrator that converts responses recorded by Fixture without connecting to Azure.
+func NewAzureReplayMigrator(configuration *api.AzureConfiguration, responses []byte) (api.Migrator, error) {
 	a, err := (&ClusterAccessor{
 		configuration: configuration,
-		ctx:           context.Background(),
-	}).init()
+	}).initReplay(responses)
 	if err != nil {
 		return nil, err
 	}
@@ -55,3 +129,13 @@ func NewAzureMigrator(configuration *api.AzureConfiguration) (api.Migrator, erro
 		accessor: a,
 	}, nil
 }
+
+// Fixture returns responses read by Convert, so that the conversion can be replayed.
+func (migrator *Migrator) Fixture() (*api.Fixture, error) {
+	recorder, ok := migrator.accessor.(api.Recorder)
+	if !ok {
+		return nil, fmt.Errorf("accessor does not record responses")
+	}
+
+	return recorder.Fixture()
+}
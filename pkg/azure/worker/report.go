@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2022-03-01/containerservice"
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+func workerPath(name string, elements ...string) string {
+	return api.Path(append([]string{"workers", "azure", name}, elements...)...)
+}
+
+// Report records values of converted worker that were not read from the agent pool.
+func Report(report *api.ConversionReport, agentPool containerservice.ManagedClusterAgentPoolProfile) {
+	name := *agentPool.Name
+	report.Defaulted(workerPath(name, "isMultiAZ"), "agent pool is always spread across discovered availability zones")
+
+	for key := range agentPool.NodeLabels {
+		if strings.HasPrefix(key, "kubernetes.azure.com") {
+			report.Dropped(workerPath(name, "spec", "nodeLabels"), "node labels with kubernetes.azure.com prefix are not converted")
+			break
+		}
+	}
+
+	if agentPool.KubeletConfig != nil {
+		report.Dropped(workerPath(name, "spec", "kubeletConfig"), "kubelet configuration is not converted")
+	}
+
+	if agentPool.LinuxOSConfig != nil {
+		report.Dropped(workerPath(name, "spec", "linuxOSConfig"), "Linux OS configuration is not converted")
+	}
+}
@@ -7,31 +7,18 @@ import (
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 )
 
-// getDefaultAzureWorkers returns nullified list of default Azure workers.
-// It is done as we don't want to create them during migration.
-// This has to be kept in sync with bootstrap/helm/cluster-api-cluster/values.yaml.
-func getDefaultAzureWorkers() api.AzureWorkers {
-	return api.AzureWorkers{
-		"lsod":   nil,
-		"lsspot": nil,
-		"msod":   nil,
-		"msspot": nil,
-		"ssod":   nil,
-		"ssspot": nil,
-	}
-}
-
 type Workers struct {
 	Cluster        *containerservice.ManagedCluster
 	ResourceGroup  string
 	SubscriptionID string
 }
 
-func (workers *Workers) Workers() *api.AzureWorkers {
-	result := getDefaultAzureWorkers()
+func (workers *Workers) Workers(report *api.ConversionReport) *api.AzureWorkers {
+	result := api.AzureWorkers{}
 
 	for _, agentPool := range *workers.Cluster.AgentPoolProfiles {
 		result[*agentPool.Name] = Worker(agentPool)
+		Report(report, agentPool)
 	}
 
 	return &result
@@ -100,10 +87,10 @@ func Worker(agentPool containerservice.ManagedClusterAgentPoolProfile) *api.Azur
 	}
 }
 
-func (workers *Workers) Convert() (*api.Workers, error) {
+func (workers *Workers) Convert(report *api.ConversionReport) (*api.Workers, error) {
 	return &api.Workers{
 		WorkersSpec: api.WorkersSpec{
-			AzureWorkers: workers.Workers(),
+			AzureWorkers: workers.Workers(report),
 		},
 	}, nil
 }
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+const (
+	OutputFormatYAML = "yaml"
+	OutputFormatJSON = "json"
+	// OutputFormatSet prints values as key=value lines for helm --set.
+	OutputFormatSet = "set"
+
+	// OutputModeValues generates values for the cluster-api-cluster chart.
+	OutputModeValues = "values"
+	// OutputModeManifests generates Cluster API objects that can be applied directly.
+	OutputModeManifests = "manifests"
+	// OutputModeTopology generates ClusterClass with its templates and Cluster that uses it.
+	OutputModeTopology = "topology"
+)
+
+// envReference matches ${ENV} references that are interpolated in string values of the parsed file.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Config describes a single migration. It can be stored in YAML or JSON file, i.e.:
+//
+//	provider: azure
+//	azure:
+//	  subscriptionID: ${AZURE_SUBSCRIPTION_ID}
+//	  resourceGroup: plural
+//	  name: plrltest2
+//	tags:
+//	  sigs.k8s.io_cluster-api-provider-azure_role: common
+//	output:
+//	  format: yaml
+//	  path: values.yaml
+//
+// Every provider registered with api.RegisterProvider, the built-in ones included, is configured in the section
+// named after the provider.
+type Config struct {
+	Provider api.ClusterProvider `json:"provider"`
+	// Tags are added to the cluster resources by the tag command.
+	Tags   map[string]string `json:"tags,omitempty"`
+	Output Output            `json:"output,omitempty"`
+	// Providers holds sections of registered providers decoded into their configurations, i.e. the azure section
+	// into api.AzureConfiguration.
+	Providers map[api.ClusterProvider]api.Validator `json:"-"`
+}
+
+type Output struct {
+	// Mode selects what is generated, one of: values, manifests, topology. Defaults to values.
+	Mode string `json:"mode,omitempty"`
+	// Format of the generated values, one of: yaml, json, set. Defaults to yaml.
+	// Manifests are written as multi-document YAML or as JSON v1 List, set is supported only for values.
+	// The report is written in YAML with the set format.
+	Format string `json:"format,omitempty"`
+	// Path of the file generated values are written to. Defaults to standard output.
+	Path string `json:"path,omitempty"`
+	// Split writes cluster and workers values as separate documents, or to files with -cluster and -workers
+	// suffixes if Path is set, i.e. values-cluster.yaml and values-workers.yaml.
+	Split bool `json:"split,omitempty"`
+	// ReportPath of the file conversion report is written to. Defaults to standard error.
+	ReportPath string `json:"reportPath,omitempty"`
+	// ChartSchema is the path of values.schema.json of the cluster-api-cluster chart, or of the chart directory.
+	// Values are validated against it before they are written, in addition to the schema of the values types.
+	ChartSchema string `json:"chartSchema,omitempty"`
+	// ChartValues is the path of values.yaml of the cluster-api-cluster chart, or of the chart directory. Worker pools
+	// the chart creates by default are set to null in the values, unless the cluster has pools with the same names.
+	ChartValues string `json:"chartValues,omitempty"`
+	// ChartProfile selects built-in default worker pools of the chart instead of ChartValues. Defaults to latest.
+	ChartProfile string `json:"chartProfile,omitempty"`
+	// Rules is the path of the rules file that rewrites worker pools of the converted values,
+	// i.e. renames them or changes their instance types. Rules are applied before overrides.
+	Rules string `json:"rules,omitempty"`
+	// Overrides are paths of partial values files deep-merged into the converted values in the given order,
+	// instead of editing the written values by hand. Changed fields are listed in the report.
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// Load reads configuration file and validates it.
+func Load(path string) (*Config, error) {
+	config, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Read reads configuration file and interpolates environment variables without validating it,
+// so that it can be completed i.e. with command line flags first.
+func Read(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Parse decodes YAML or JSON configuration and interpolates ${ENV} references in its string values.
+func Parse(data []byte) (*Config, error) {
+	data, err := interpolate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err = yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// interpolate replaces ${ENV} references in string values of the parsed document and returns it as JSON.
+// References in comments and keys are left alone and values are never parsed as YAML again,
+// so that they cannot change structure of the document.
+func interpolate(data []byte) ([]byte, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err = decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	document = interpolateValue(document, &missing)
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("environment variables %v referenced in configuration are not set", missing)
+	}
+
+	return json.Marshal(document)
+}
+
+func interpolateValue(value interface{}, missing *[]string) interface{} {
+	switch value := value.(type) {
+	case string:
+		return envReference.ReplaceAllStringFunc(value, func(reference string) string {
+			name := envReference.FindStringSubmatch(reference)[1]
+			result, ok := os.LookupEnv(name)
+			if !ok {
+				*missing = append(*missing, name)
+			}
+
+			return result
+		})
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = interpolateValue(item, missing)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = interpolateValue(item, missing)
+		}
+	}
+
+	return value
+}
+
+// Validate checks that configuration for the selected provider is present and complete.
+// It does not connect to the cloud provider.
+func (config *Config) Validate() error {
+	if len(config.Provider) == 0 {
+		return &api.InvalidConfigError{Err: fmt.Errorf("provider cannot be empty, ensure that it is set")}
+	}
+
+	providerConfig, err := config.Configuration().ProviderConfiguration(config.Provider)
+	if err != nil {
+		return &api.InvalidConfigError{Err: err}
+	}
+
+	if err = providerConfig.Validate(); err != nil {
+		return &api.InvalidConfigError{Err: err}
+	}
+
+	return config.Output.Validate()
+}
+
+func (output Output) Validate() error {
+	switch output.Format {
+	case "", OutputFormatYAML, OutputFormatJSON, OutputFormatSet:
+	default:
+		return &api.InvalidConfigError{Err: fmt.Errorf("unsupported output format %q, use %s, %s or %s", output.Format, OutputFormatYAML, OutputFormatJSON, OutputFormatSet)}
+	}
+
+	switch output.Mode {
+	case "", OutputModeValues, OutputModeManifests, OutputModeTopology:
+	default:
+		return &api.InvalidConfigError{Err: fmt.Errorf("unsupported output mode %q, use %s, %s or %s", output.Mode, OutputModeValues, OutputModeManifests, OutputModeTopology)}
+	}
+
+	if len(output.ChartValues) > 0 && len(output.ChartProfile) > 0 {
+		return &api.InvalidConfigError{Err: fmt.Errorf("chart values and chart profile cannot be used together")}
+	}
+	if len(output.ChartProfile) > 0 {
+		if _, err := api.ChartProfile(output.ChartProfile); err != nil {
+			return &api.InvalidConfigError{Err: err}
+		}
+	}
+
+	if output.Mode != "" && output.Mode != OutputModeValues {
+		if output.Format == OutputFormatSet {
+			return &api.InvalidConfigError{Err: fmt.Errorf("output format %s is supported only for values", OutputFormatSet)}
+		}
+		if output.Split {
+			return &api.InvalidConfigError{Err: fmt.Errorf("only values can be split into cluster and workers")}
+		}
+	}
+
+	return nil
+}
+
+// Configuration returns migrator configuration for the selected provider.
+func (config *Config) Configuration() *api.Configuration {
+	return &api.Configuration{Providers: config.Providers}
+}
+
+// Section returns configuration of the provider. Missing section is created with NewConfiguration
+// of the provider registration, so that it can be completed i.e. with command line flags.
+func (config *Config) Section(provider api.ClusterProvider) (api.Validator, error) {
+	if section, ok := config.Providers[provider]; ok && section != nil {
+		return section, nil
+	}
+
+	registration, ok := api.LookupProvider(provider)
+	if !ok {
+		return nil, &api.InvalidConfigError{Err: fmt.Errorf("unsupported provider %q", provider)}
+	}
+
+	if config.Providers == nil {
+		config.Providers = map[api.ClusterProvider]api.Validator{}
+	}
+	config.Providers[provider] = registration.NewConfiguration()
+
+	return config.Providers[provider], nil
+}
+
+// UnmarshalJSON decodes sections of registered providers into their configurations
+// and fails on any other unknown field.
+func (config *Config) UnmarshalJSON(data []byte) error {
+	sections := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+
+	fields := jsonFields(reflect.TypeOf(*config))
+	known := map[string]json.RawMessage{}
+	for key, section := range sections {
+		if fields[key] {
+			known[key] = section
+			continue
+		}
+
+		registration, ok := api.LookupProvider(api.ClusterProvider(key))
+		if !ok {
+			return fmt.Errorf("json: unknown field %q", key)
+		}
+
+		providerConfig := registration.NewConfiguration()
+		if err := decodeStrict(section, providerConfig); err != nil {
+			return fmt.Errorf("invalid %s configuration: %w", key, err)
+		}
+
+		if config.Providers == nil {
+			config.Providers = map[api.ClusterProvider]api.Validator{}
+		}
+		config.Providers[registration.Name] = providerConfig
+	}
+
+	data, err := json.Marshal(known)
+	if err != nil {
+		return err
+	}
+
+	// plain has the same fields as Config, but not its UnmarshalJSON method.
+	type plain Config
+	return decodeStrict(data, (*plain)(config))
+}
+
+func decodeStrict(data []byte, target interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(target)
+}
+
+// jsonFields returns names of the JSON fields of the struct type.
+func jsonFields(t reflect.Type) map[string]bool {
+	result := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if len(name) > 0 && name != "-" {
+			result[name] = true
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	// The azure section is decoded by the registered provider.
+	_ "github.com/pluralsh/cluster-api-migration/pkg/azure"
+)
+
+func TestParseInterpolatesStringValues(t *testing.T) {
+	t.Setenv("TEST_RESOURCE_GROUP", "plural: test # not a comment\nname: other")
+
+	config, err := Parse([]byte(`
+# ${TEST_UNSET} in a comment is not interpolated.
+provider: azure
+azure:
+  subscriptionID: sub-${TEST_RESOURCE_GROUP}
+  resourceGroup: ${TEST_RESOURCE_GROUP}
+  name: plrltest2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Provider != api.ClusterProviderAzure {
+		t.Errorf("provider = %q, want %q", config.Provider, api.ClusterProviderAzure)
+	}
+
+	azure, ok := config.Providers[api.ClusterProviderAzure].(*api.AzureConfiguration)
+	if !ok {
+		t.Fatalf("azure configuration = %T, want *api.AzureConfiguration", config.Providers[api.ClusterProviderAzure])
+	}
+	if want := "plural: test # not a comment\nname: other"; azure.ResourceGroup != want {
+		t.Errorf("resourceGroup = %q, want %q", azure.ResourceGroup, want)
+	}
+	if want := "sub-plural: test # not a comment\nname: other"; azure.SubscriptionID != want {
+		t.Errorf("subscriptionID = %q, want %q", azure.SubscriptionID, want)
+	}
+	if azure.Name != "plrltest2" {
+		t.Errorf("name = %q, want plrltest2", azure.Name)
+	}
+}
+
+func TestParseFailsOnUnsetVariables(t *testing.T) {
+	_, err := Parse([]byte(`
+provider: azure
+azure:
+  subscriptionID: ${TEST_UNSET_B}
+  resourceGroup: ${TEST_UNSET_A}
+`))
+	if err == nil {
+		t.Fatal("expected error for unset variables")
+	}
+
+	if want := "environment variables [TEST_UNSET_A TEST_UNSET_B] referenced in configuration are not set"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
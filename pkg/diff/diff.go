@@ -0,0 +1,321 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+type ChangeType string
+
+const (
+	ChangeTypeAdded   = ChangeType("added")
+	ChangeTypeRemoved = ChangeType("removed")
+	ChangeTypeChanged = ChangeType("changed")
+)
+
+// listKeys are fields that identify items of lists in the values, in the order they are tried,
+// i.e. id of AWS subnets, name of GCP subnets or Azure addon profiles and ARN of IAM mappings.
+var listKeys = []string{"id", "name", "rolearn", "userarn", "key"}
+
+// Change is a difference of a single field between two values documents.
+type Change struct {
+	Type ChangeType  `json:"type"`
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+	// item is set for items added to or removed from lists, as opposed to fields of objects.
+	item bool
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeTypeAdded:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, format(c.To))
+	case ChangeTypeRemoved:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, format(c.From))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Type, c.Path, format(c.From), format(c.To))
+	}
+}
+
+// Values returns changes that turn values from into values to, ordered by path.
+func Values(from, to *api.Values) ([]Change, error) {
+	fromDocument, err := toMap(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toDocument, err := toMap(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	compare("", fromDocument, toDocument, &changes)
+	return changes, nil
+}
+
+// Drift returns changes of the actual values read from the cloud against the ones managed by Cluster API.
+// Fields the managed values do not set are not managed, so their values in the cloud are not a drift.
+// Empty objects and lists are not set either, as Cluster API objects omit them. Added objects, i.e. node pools,
+// and list items are still reported. Changes of the unmanaged paths and of fields under them are left out,
+// i.e. of fields that Cluster API objects cannot hold, see manifests.Unmanaged.
+func Drift(managed, actual *api.Values, unmanaged ...string) ([]Change, error) {
+	changes, err := Values(managed, actual)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		unset := (change.Type == ChangeTypeAdded && !change.item) || (change.Type == ChangeTypeChanged && change.From == nil)
+		if unset && (!isComposite(change.To) || isEmpty(change.To)) {
+			continue
+		}
+
+		if under(change.Path, unmanaged) {
+			continue
+		}
+
+		result = append(result, change)
+	}
+
+	return result, nil
+}
+
+// under returns true if the path is one of the paths or a field or item under them.
+func under(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compare(path string, from, to interface{}, changes *[]Change) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		for _, key := range keys(fromMap, toMap) {
+			fromValue, inFrom := fromMap[key]
+			toValue, inTo := toMap[key]
+			keyPath := join(path, key)
+
+			switch {
+			case !inTo:
+				*changes = append(*changes, Change{Type: ChangeTypeRemoved, Path: keyPath, From: fromValue})
+			case !inFrom:
+				*changes = append(*changes, Change{Type: ChangeTypeAdded, Path: keyPath, To: toValue})
+			default:
+				compare(keyPath, fromValue, toValue, changes)
+			}
+		}
+
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		compareLists(path, fromList, toList, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Type: ChangeTypeChanged, Path: path, From: from, To: to})
+	}
+}
+
+// compareLists matches items of the lists by their key, so that reordered items are not reported as changed.
+// Lists of scalars are compared as sets and lists of objects without a unique key by position.
+func compareLists(path string, from, to []interface{}, changes *[]Change) {
+	if key, ok := listKey(from, to); ok {
+		fromItems := make(map[string]interface{}, len(from))
+		toItems := make(map[string]interface{}, len(to))
+		for _, item := range from {
+			fromItems[itemKey(item, key)] = item
+		}
+		for _, item := range to {
+			toItems[itemKey(item, key)] = item
+		}
+
+		for _, value := range keys(fromItems, toItems) {
+			itemPath := fmt.Sprintf("%s[%s=%s]", path, key, value)
+			fromItem, inFrom := fromItems[value]
+			toItem, inTo := toItems[value]
+
+			switch {
+			case !inTo:
+				*changes = append(*changes, Change{Type: ChangeTypeRemoved, Path: itemPath, From: fromItem, item: true})
+			case !inFrom:
+				*changes = append(*changes, Change{Type: ChangeTypeAdded, Path: itemPath, To: toItem, item: true})
+			default:
+				compare(itemPath, fromItem, toItem, changes)
+			}
+		}
+
+		return
+	}
+
+	if scalars(from) && scalars(to) {
+		fromItems, toItems := map[string]interface{}{}, map[string]interface{}{}
+		for _, item := range from {
+			fromItems[format(item)] = item
+		}
+		for _, item := range to {
+			toItems[format(item)] = item
+		}
+
+		for _, value := range keys(fromItems, toItems) {
+			if _, ok := toItems[value]; !ok {
+				*changes = append(*changes, Change{Type: ChangeTypeRemoved, Path: path, From: fromItems[value], item: true})
+			}
+			if _, ok := fromItems[value]; !ok {
+				*changes = append(*changes, Change{Type: ChangeTypeAdded, Path: path, To: toItems[value], item: true})
+			}
+		}
+
+		return
+	}
+
+	for i := 0; i < len(from) || i < len(to); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(to):
+			*changes = append(*changes, Change{Type: ChangeTypeRemoved, Path: itemPath, From: from[i], item: true})
+		case i >= len(from):
+			*changes = append(*changes, Change{Type: ChangeTypeAdded, Path: itemPath, To: to[i], item: true})
+		default:
+			compare(itemPath, from[i], to[i], changes)
+		}
+	}
+}
+
+// listKey returns the first of listKeys that identifies every item of both lists.
+func listKey(lists ...[]interface{}) (string, bool) {
+	for _, key := range listKeys {
+		if identifies(key, lists...) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+func identifies(key string, lists ...[]interface{}) bool {
+	for _, list := range lists {
+		seen := map[string]struct{}{}
+		for _, item := range list {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return false
+			}
+
+			value, ok := object[key].(string)
+			if !ok || len(value) == 0 {
+				return false
+			}
+
+			if _, ok = seen[value]; ok {
+				return false
+			}
+			seen[value] = struct{}{}
+		}
+	}
+
+	return true
+}
+
+func itemKey(item interface{}, key string) string {
+	return item.(map[string]interface{})[key].(string)
+}
+
+func scalars(list []interface{}) bool {
+	for _, item := range list {
+		if isComposite(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toMap converts values to their JSON form, so that documents read from files and converted ones compare equal.
+func toMap(values *api.Values) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if values == nil {
+		return result, nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func keys(maps ...map[string]interface{}) []string {
+	set := map[string]struct{}{}
+	for _, m := range maps {
+		for key := range m {
+			set[key] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func join(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func isComposite(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
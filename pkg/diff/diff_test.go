@@ -0,0 +1,412 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+const base = `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1a
+        - eu-central-1b
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: no-execute
+          key: dedicated
+          value: gpu
+`
+
+func decode(t *testing.T, document string) *api.Values {
+	t.Helper()
+
+	values, _, err := api.DecodeValues([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return values
+}
+
+// summary returns type and path of every change, values are left out to keep the expectations short.
+func summary(changes []Change) []string {
+	result := make([]string, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, string(change.Type)+" "+change.Path)
+	}
+
+	return result
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		to       string
+		expected []string
+	}{
+		{
+			name:     "same values",
+			to:       base,
+			expected: []string{},
+		},
+		{
+			name: "changed scalar",
+			to: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.25
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1a
+        - eu-central-1b
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: no-execute
+          key: dedicated
+          value: gpu
+`,
+			expected: []string{"changed cluster.kubernetesVersion"},
+		},
+		{
+			name: "added and removed pools are matched by name",
+			to: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+workers:
+  aws:
+    large:
+      spec:
+        instanceType: m5.2xlarge
+`,
+			expected: []string{"added workers.aws.large", "removed workers.aws.small"},
+		},
+		{
+			name: "reordered list items are matched by id",
+			to: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1b
+        - eu-central-1a
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: no-execute
+          key: dedicated
+          value: gpu
+`,
+			expected: []string{},
+		},
+		{
+			name: "list items changed, added and removed by id",
+			to: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-2
+        cidrBlock: 10.0.64.0/19
+        availabilityZone: eu-central-1b
+      - id: subnet-3
+        cidrBlock: 10.0.96.0/19
+        availabilityZone: eu-central-1c
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1a
+        - eu-central-1b
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: no-execute
+          key: dedicated
+          value: gpu
+`,
+			expected: []string{
+				"removed cluster.aws.network.subnets[id=subnet-1]",
+				"changed cluster.aws.network.subnets[id=subnet-2].cidrBlock",
+				"added cluster.aws.network.subnets[id=subnet-3]",
+			},
+		},
+		{
+			name: "lists of scalars are compared as sets and lists without unique key by position",
+			to: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1b
+        - eu-central-1c
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: prefer-no-schedule
+          key: dedicated
+          value: gpu
+`,
+			expected: []string{
+				"removed workers.aws.small.spec.availabilityZones",
+				"added workers.aws.small.spec.availabilityZones",
+				"changed workers.aws.small.spec.taints[1].effect",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := Values(decode(t, base), decode(t, test.to))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual := summary(changes); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("changes = %v, expected %v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		actual    string
+		unmanaged []string
+		expected  []string
+	}{
+		{
+			name: "fields not set by the managed values are skipped",
+			actual: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    sshKeyName: default
+    additionalTags: {}
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1a
+        - eu-central-1b
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: no-execute
+          key: dedicated
+          value: gpu
+`,
+			expected: []string{},
+		},
+		{
+			name: "changed fields, added pools and list items are reported",
+			actual: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.25
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+      - id: subnet-3
+        cidrBlock: 10.0.64.0/19
+        availabilityZone: eu-central-1c
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.xlarge
+        availabilityZones:
+        - eu-central-1a
+        - eu-central-1b
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: no-execute
+          key: dedicated
+          value: gpu
+    large:
+      spec:
+        instanceType: m5.2xlarge
+`,
+			expected: []string{
+				"added cluster.aws.network.subnets[id=subnet-3]",
+				"changed cluster.kubernetesVersion",
+				"added workers.aws.large",
+				"changed workers.aws.small.spec.instanceType",
+			},
+		},
+		{
+			name: "unmanaged paths and fields under them are skipped",
+			actual: `
+apiVersion: v1alpha2
+provider: aws
+cluster:
+  name: test
+  kubernetesVersion: v1.24
+  aws:
+    region: eu-central-1
+    network:
+      subnets:
+      - id: subnet-1
+        cidrBlock: 10.0.0.0/19
+        availabilityZone: eu-central-1a
+      - id: subnet-2
+        cidrBlock: 10.0.32.0/19
+        availabilityZone: eu-central-1b
+workers:
+  aws:
+    small:
+      spec:
+        instanceType: t3.large
+        availabilityZones:
+        - eu-central-1a
+        - eu-central-1b
+        taints:
+        - effect: no-schedule
+          key: dedicated
+          value: gpu
+        - effect: prefer-no-schedule
+          key: dedicated
+          value: gpu
+`,
+			unmanaged: []string{"workers.aws.small.spec.taints"},
+			expected:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := Drift(decode(t, base), decode(t, test.actual), test.unmanaged...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual := summary(changes); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("changes = %v, expected %v", actual, test.expected)
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package gcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 
 	container "cloud.google.com/go/container/apiv1"
@@ -23,43 +23,91 @@ import (
 
 type ClusterAccessor struct {
 	configuration    *api.GCPConfiguration
-	ctx              context.Context
 	clusterClient    *container.ClusterManagerClient
 	computeClient    *compute.Service
 	kubernetesClient *kubernetes.Clientset
+	// snapshot holds responses read by GetCluster and GetWorkers, or responses to replay.
+	snapshot *Snapshot
+	replay   bool
 }
 
-func (this *ClusterAccessor) AddClusterTags(Tags map[string]string) error {
+func (this *ClusterAccessor) PlanClusterTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (this *ClusterAccessor) AddMachinePollsTags(Tags map[string]string) error {
+func (this *ClusterAccessor) PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (this *ClusterAccessor) AddVirtualNetworkTags(tags map[string]string) error {
+const resourceKindNetwork = "vpc-network"
+
+// PlanVirtualNetworkTags skips the VPC network of the cluster, as Compute Engine networks do not support labels.
+func (this *ClusterAccessor) PlanVirtualNetworkTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	if this.replay {
+		return api.ErrReplay
+	}
+
+	c, err := this.getCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	plan.Skip(resourceKindNetwork, c.GetNetwork(), "GCP networks do not support labels")
 	return nil
 }
 
-func (this *ClusterAccessor) init() (api.ClusterAccessor, error) {
-	err := this.initContainerClient()
+func (this *ClusterAccessor) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	return nil
+}
+
+func (this *ClusterAccessor) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	return nil
+}
+
+func (this *ClusterAccessor) init(ctx context.Context) (api.ClusterAccessor, error) {
+	err := this.initContainerClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	err = this.initComputeClient()
+	err = this.initComputeClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	err = this.initKubernetesClient()
-	return this, err
+	if err != nil {
+		return nil, err
+	}
+
+	this.snapshot = &Snapshot{}
+	return this, nil
+}
+
+// initReplay makes the accessor convert recorded responses without connecting to GCP and the cluster.
+func (this *ClusterAccessor) initReplay(responses []byte) (api.ClusterAccessor, error) {
+	this.snapshot = &Snapshot{}
+	if err := json.Unmarshal(responses, this.snapshot); err != nil {
+		return nil, fmt.Errorf("invalid GCP responses: %w", err)
+	}
+
+	this.replay = true
+	return this, nil
+}
+
+func (this *ClusterAccessor) Fixture() (*api.Fixture, error) {
+	return api.NewFixture(api.ClusterProviderGCP, this.configuration, this.snapshot)
 }
 
-func (this *ClusterAccessor) initContainerClient() error {
+func (this *ClusterAccessor) initContainerClient(ctx context.Context) error {
+	options, err := this.containerClientOptions()
+	if err != nil {
+		return err
+	}
+
 	client, err := container.NewClusterManagerClient(
-		this.ctx,
-		this.defaultClientOptions()...,
+		ctx,
+		options...,
 	)
 
 	if err != nil {
@@ -70,10 +118,15 @@ func (this *ClusterAccessor) initContainerClient() error {
 	return nil
 }
 
-func (this *ClusterAccessor) initComputeClient() error {
+func (this *ClusterAccessor) initComputeClient(ctx context.Context) error {
+	options, err := this.computeClientOptions(ctx)
+	if err != nil {
+		return err
+	}
+
 	client, err := compute.NewService(
-		this.ctx,
-		this.defaultClientOptions()...,
+		ctx,
+		options...,
 	)
 
 	if err != nil {
@@ -113,33 +166,26 @@ func (this *ClusterAccessor) clusterName(project, region, name string) string {
 	)
 }
 
-func (this *ClusterAccessor) getClusterOrDie() *containerpb.Cluster {
-	cluster, err := this.clusterClient.GetCluster(this.ctx, &containerpb.GetClusterRequest{
-		Name: this.clusterName(this.configuration.Project, this.configuration.Region, this.configuration.Name),
+func (this *ClusterAccessor) getCluster(ctx context.Context) (*containerpb.Cluster, error) {
+	name := this.clusterName(this.configuration.Project, this.configuration.Region, this.configuration.Name)
+	cluster, err := this.clusterClient.GetCluster(ctx, &containerpb.GetClusterRequest{
+		Name: name,
 	})
 
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return cluster
+	return cluster, wrapError(fmt.Sprintf("GKE cluster %s", name), err)
 }
 
-func (this *ClusterAccessor) getNetworkOrDie(name string) *compute.Network {
+func (this *ClusterAccessor) getNetwork(ctx context.Context, name string) (*compute.Network, error) {
 	req := this.computeClient.Networks.Get(this.configuration.Project, name)
-	network, err := req.Do()
-
-	if err != nil {
-		log.Fatal(err)
-	}
+	network, err := req.Context(ctx).Do()
 
-	return network
+	return network, wrapError(fmt.Sprintf("network %s", name), err)
 }
 
-func (this *ClusterAccessor) getSubnetworksOrDie(network string) []*compute.Subnetwork {
+func (this *ClusterAccessor) getSubnetworks(ctx context.Context, network string) ([]*compute.Subnetwork, error) {
 	result := make([]*compute.Subnetwork, 0)
 	r := this.computeClient.Subnetworks.List(this.configuration.Project, this.configuration.Region)
-	if err := r.Pages(this.ctx, func(page *compute.SubnetworkList) error {
+	if err := r.Pages(ctx, func(page *compute.SubnetworkList) error {
 		for _, subnetwork := range page.Items {
 			if strings.HasSuffix(subnetwork.Network, network) {
 				result = append(result, subnetwork)
@@ -148,34 +194,70 @@ func (this *ClusterAccessor) getSubnetworksOrDie(network string) []*compute.Subn
 
 		return nil
 	}); err != nil {
-		log.Fatal(err)
+		return nil, wrapError(fmt.Sprintf("subnetworks of network %s", network), err)
 	}
 
-	return result
+	return result, nil
 }
 
-func (this *ClusterAccessor) getNodesOrDie() *corev1.NodeList {
-	nodes, err := this.kubernetesClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		log.Fatal(err)
-	}
+func (this *ClusterAccessor) getNodes(ctx context.Context) (*corev1.NodeList, error) {
+	nodes, err := this.kubernetesClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 
-	return nodes
+	return nodes, wrapError("cluster nodes", err)
 }
 
-func (this *ClusterAccessor) GetCluster() (*api.Cluster, error) {
-	c := this.getClusterOrDie()
-	network := this.getNetworkOrDie(c.Network)
-	subnetworks := this.getSubnetworksOrDie(network.Name)
-	gcpCluster := cluster.NewGCPCluster(this.configuration.Project, c, network, subnetworks)
+func (this *ClusterAccessor) GetCluster(ctx context.Context, report *api.ConversionReport) (*api.Cluster, error) {
+	if !this.replay {
+		c, err := this.getCluster(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		network, err := this.getNetwork(ctx, c.Network)
+		if err != nil {
+			return nil, err
+		}
+
+		subnetworks, err := this.getSubnetworks(ctx, network.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		this.snapshot.Cluster = c
+		this.snapshot.Network = network
+		this.snapshot.Subnetworks = subnetworks
+	}
 
-	return gcpCluster.Convert(), nil
+	if this.snapshot.Cluster == nil || this.snapshot.Network == nil {
+		return nil, fmt.Errorf("GKE cluster %s: responses were not recorded", this.configuration.Name)
+	}
+
+	gcpCluster := cluster.NewGCPCluster(this.configuration.Project, this.snapshot.Cluster, this.snapshot.Network, this.snapshot.Subnetworks)
+
+	return gcpCluster.Convert(report), nil
 }
 
-func (this *ClusterAccessor) GetWorkers() (*api.Workers, error) {
-	cluster := this.getClusterOrDie()
-	nodes := this.getNodesOrDie()
-	workers := worker.NewGCPWorkers(cluster, nodes)
+func (this *ClusterAccessor) GetWorkers(ctx context.Context, report *api.ConversionReport) (*api.Workers, error) {
+	if !this.replay {
+		cluster, err := this.getCluster(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes, err := this.getNodes(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		this.snapshot.Cluster = cluster
+		this.snapshot.Nodes = nodes
+	}
+
+	if this.snapshot.Cluster == nil || this.snapshot.Nodes == nil {
+		return nil, fmt.Errorf("GKE cluster %s: node responses were not recorded", this.configuration.Name)
+	}
+
+	workers := worker.NewGCPWorkers(this.snapshot.Cluster, this.snapshot.Nodes)
 
-	return workers.Convert(), nil
+	return workers.Convert(report), nil
 }
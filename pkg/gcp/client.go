@@ -0,0 +1,153 @@
+package gcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// containerClientOptions returns options of the GKE client. It talks gRPC, so the CA bundle is set
+// with transport credentials and the proxy is reached with HTTP CONNECT.
+func (this *ClusterAccessor) containerClientOptions() ([]option.ClientOption, error) {
+	options := this.defaultClientOptions()
+	plaintext := false
+	if endpoint := this.configuration.Endpoints.Container; len(endpoint) > 0 {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		address := u.Host
+		if len(u.Port()) == 0 {
+			address = net.JoinHostPort(u.Hostname(), map[string]string{"http": "80", "https": "443"}[u.Scheme])
+		}
+		options = append(options, option.WithEndpoint(address))
+
+		// Emulators usually serve plain gRPC and do not check credentials.
+		if u.Scheme == "http" {
+			plaintext = true
+			options = append(options,
+				option.WithoutAuthentication(),
+				option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			)
+		}
+	}
+
+	tlsConfig, err := this.configuration.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil && !plaintext {
+		options = append(options, option.WithGRPCDialOption(grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))))
+	}
+
+	proxy, err := this.configuration.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		options = append(options, option.WithGRPCDialOption(grpc.WithContextDialer(dialProxy(proxy))))
+	}
+
+	return options, nil
+}
+
+// computeClientOptions returns options of the Compute Engine client. Proxy and CA bundle are set
+// on the base transport that is wrapped with authentication.
+func (this *ClusterAccessor) computeClientOptions(ctx context.Context) ([]option.ClientOption, error) {
+	options := this.defaultClientOptions()
+	if endpoint := this.configuration.Endpoints.Compute; len(endpoint) > 0 {
+		options = append(options, option.WithEndpoint(endpoint))
+	}
+
+	if this.configuration.ClientOptions.IsZero() {
+		return options, nil
+	}
+
+	httpClient, err := this.configuration.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := htransport.NewTransport(ctx, httpClient.Transport, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(options, option.WithHTTPClient(&http.Client{Transport: transport})), nil
+}
+
+// dialProxy returns gRPC dialer that opens a tunnel to the address with HTTP CONNECT request to the proxy.
+func dialProxy(proxy *url.URL) func(ctx context.Context, address string) (net.Conn, error) {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		proxyAddress := proxy.Host
+		if len(proxy.Port()) == 0 {
+			proxyAddress = net.JoinHostPort(proxy.Hostname(), map[string]string{"http": "80", "https": "443"}[proxy.Scheme])
+		}
+
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddress)
+		if err != nil {
+			return nil, err
+		}
+		if proxy.Scheme == "https" {
+			conn = tls.Client(conn, &tls.Config{ServerName: proxy.Hostname(), MinVersion: tls.VersionTLS12})
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+			defer conn.SetDeadline(time.Time{})
+		}
+
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Host: address},
+			Host:   address,
+			Header: http.Header{},
+		}
+		if proxy.User != nil {
+			password, _ := proxy.User.Password()
+			auth := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+			req.Header.Set("Proxy-Authorization", "Basic "+auth)
+		}
+
+		if err = req.Write(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, req)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			conn.Close()
+			return nil, fmt.Errorf("proxy %s refused tunnel to %s: %s", proxy.Host, address, resp.Status)
+		}
+
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+}
+
+// bufferedConn reads data the proxy sent right after the CONNECT response before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
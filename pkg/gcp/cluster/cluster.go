@@ -52,10 +52,12 @@ func (this *Cluster) ReleaseChannel() *api.GCPReleaseChannel {
 }
 
 func (this *Cluster) KubernetesVersion() string {
-	return this.GetCurrentMasterVersion()
+	return api.KubernetesVersion(this.GetCurrentMasterVersion())
 }
 
-func (this *Cluster) Convert() *api.Cluster {
+func (this *Cluster) Convert(report *api.ConversionReport) *api.Cluster {
+	this.Report(report)
+
 	return &api.Cluster{
 		Name:              this.GetName(),
 		PodCIDRBlocks:     this.CIDRBlocks(),
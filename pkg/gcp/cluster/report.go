@@ -0,0 +1,20 @@
+package cluster
+
+import (
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// Report records values of converted cluster that were not read from the GKE cluster.
+func (this *Cluster) Report(report *api.ConversionReport) {
+	if len(this.GetServicesIpv4Cidr()) > 0 {
+		report.Dropped(api.Path("cluster", "serviceCidrBlocks"), "cluster service CIDR block %s is not converted", this.GetServicesIpv4Cidr())
+	}
+
+	if this.GetMasterAuthorizedNetworksConfig().GetEnabled() {
+		report.Dropped(api.Path("cluster", "gcp"), "master authorized networks are not converted")
+	}
+
+	if this.GetPrivateClusterConfig() != nil {
+		report.Dropped(api.Path("cluster", "gcp"), "private cluster configuration is not converted")
+	}
+}
@@ -0,0 +1,62 @@
+package gcp
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// wrapError converts errors returned by container, compute and Kubernetes clients into typed API errors.
+func wrapError(resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return api.NewError(errorClass(err), resource, err)
+}
+
+func errorClass(err error) api.ErrorClass {
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		switch googleErr.Code {
+		case http.StatusNotFound:
+			return api.ErrorClassNotFound
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return api.ErrorClassPermissionDenied
+		case http.StatusTooManyRequests:
+			return api.ErrorClassThrottled
+		}
+
+		return api.ErrorClassUnknown
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.NotFound:
+			return api.ErrorClassNotFound
+		case codes.PermissionDenied, codes.Unauthenticated:
+			return api.ErrorClassPermissionDenied
+		case codes.ResourceExhausted:
+			return api.ErrorClassThrottled
+		}
+
+		return api.ErrorClassUnknown
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		return api.ErrorClassNotFound
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return api.ErrorClassPermissionDenied
+	case apierrors.IsTooManyRequests(err):
+		return api.ErrorClassThrottled
+	}
+
+	return api.ErrorClassUnknown
+}
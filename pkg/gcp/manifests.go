@@ -0,0 +1,334 @@
+package gcp
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+)
+
+// CAPG API types are not vendored, objects are rendered as unstructured ones following the v1beta1 schema.
+var (
+	infrastructureGroupVersion = schema.GroupVersion{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1"}
+	gcpManagedCluster          = infrastructureGroupVersion.WithKind("GCPManagedCluster")
+	gcpManagedControlPlane     = infrastructureGroupVersion.WithKind("GCPManagedControlPlane")
+	gcpManagedMachinePool      = infrastructureGroupVersion.WithKind("GCPManagedMachinePool")
+)
+
+// taintEffects are taint effects of GCPManagedMachinePool. GCP workers in the values use the same Kubernetes names.
+var taintEffects = map[api.GCPTaintEffect]bool{
+	api.GCPTaintEffectNoSchedule:       true,
+	api.GCPTaintEffectNoExecute:        true,
+	api.GCPTaintEffectPreferNoSchedule: true,
+}
+
+// renderManifests returns Cluster, GCPManagedCluster, GCPManagedControlPlane and a MachinePool
+// with GCPManagedMachinePool for every worker. GKE cluster and node pool names are set explicitly,
+// so that CAPG adopts the existing ones.
+func renderManifests(values *api.Values, report *api.ConversionReport) ([]runtime.Object, error) {
+	spec := values.Cluster.GCPCloudSpec
+	if spec == nil {
+		return nil, fmt.Errorf("values do not contain gcp cluster")
+	}
+
+	name := values.Cluster.Name
+	controlPlaneName := fmt.Sprintf("%s-control-plane", name)
+
+	clusterSpec, err := managedClusterSpec(spec, report)
+	if err != nil {
+		return nil, err
+	}
+
+	controlPlaneSpec := map[string]interface{}{
+		"clusterName":     name,
+		"project":         spec.Project,
+		"location":        spec.Region,
+		"enableAutopilot": spec.EnableAutopilot,
+	}
+	if len(values.Cluster.KubernetesVersion) > 0 {
+		controlPlaneSpec["controlPlaneVersion"] = values.Cluster.KubernetesVersion
+	}
+	if spec.ReleaseChannel != nil {
+		controlPlaneSpec["releaseChannel"] = string(*spec.ReleaseChannel)
+	}
+	if spec.EnableWorkloadIdentity {
+		report.Dropped("cluster.gcp.enableWorkloadIdentity", "workload identity cannot be set in GCPManagedControlPlane")
+	}
+	if spec.AddonsConfig != nil {
+		report.Dropped("cluster.gcp.addonsConfig", "addons cannot be set in GCPManagedControlPlane")
+	}
+
+	cluster := manifests.NewCluster(
+		values.Cluster,
+		nil,
+		manifests.Reference(gcpManagedControlPlane, controlPlaneName),
+		manifests.Reference(gcpManagedCluster, name),
+	)
+
+	result := []runtime.Object{
+		cluster,
+		manifests.NewObject(gcpManagedCluster, name, clusterSpec),
+		manifests.NewObject(gcpManagedControlPlane, controlPlaneName, controlPlaneSpec),
+	}
+	if values.Workers.GCPWorkers == nil {
+		return result, nil
+	}
+
+	workers := *values.Workers.GCPWorkers
+	names := make([]string, 0, len(workers))
+	for workerName := range workers {
+		names = append(names, workerName)
+	}
+	sort.Strings(names)
+
+	for _, workerName := range names {
+		worker := workers[workerName]
+		if worker == nil {
+			continue
+		}
+
+		poolSpec, err := machinePoolSpec(workerName, worker.Spec, report)
+		if err != nil {
+			return nil, err
+		}
+
+		version := values.Cluster.KubernetesVersion
+		versionPath := "cluster.kubernetesVersion"
+		if worker.KubernetesVersion != nil {
+			version = *worker.KubernetesVersion
+			versionPath = api.Path("workers", "gcp", workerName, "kubernetesVersion")
+		}
+
+		poolName := fmt.Sprintf("%s-%s", name, workerName)
+		machinePool := manifests.NewMachinePool(
+			name,
+			poolName,
+			worker.Replicas,
+			manifests.MachinePoolVersion(version, versionPath, report),
+			worker.Labels,
+			worker.Annotations,
+			manifests.Reference(gcpManagedMachinePool, poolName),
+		)
+
+		result = append(result, machinePool, manifests.NewObject(gcpManagedMachinePool, poolName, poolSpec))
+	}
+
+	return result, nil
+}
+
+func managedClusterSpec(spec *api.GCPCloudSpec, report *api.ConversionReport) (map[string]interface{}, error) {
+	result := map[string]interface{}{
+		"project": spec.Project,
+		"region":  spec.Region,
+	}
+
+	if spec.AdditionalLabels != nil {
+		labels, err := manifests.ToMap(spec.AdditionalLabels)
+		if err != nil {
+			return nil, err
+		}
+		result["additionalLabels"] = labels
+	}
+
+	if spec.Network == nil {
+		return result, nil
+	}
+
+	network := map[string]interface{}{
+		"name":                  spec.Network.Name,
+		"autoCreateSubnetworks": spec.Network.AutoCreateSubnetworks,
+	}
+	if len(spec.Network.DatapathProvider) > 0 {
+		report.Dropped("cluster.gcp.network.datapathProvider", "datapath provider cannot be set in GCPManagedCluster")
+	}
+
+	subnets := make([]interface{}, 0, len(spec.Subnets))
+	for _, subnet := range spec.Subnets {
+		s, err := manifests.ToMap(subnet)
+		if err != nil {
+			return nil, err
+		}
+		s["region"] = spec.Region
+		subnets = append(subnets, s)
+	}
+	if len(subnets) > 0 {
+		network["subnets"] = subnets
+	}
+
+	result["network"] = network
+	return result, nil
+}
+
+func machinePoolSpec(name string, spec api.GCPWorkerSpec, report *api.ConversionReport) (map[string]interface{}, error) {
+	path := api.Path("workers", "gcp", name, "spec")
+	if spec.Preemptible {
+		report.Dropped(api.Path(path, "preemptible"), "preemptible nodes cannot be set in GCPManagedMachinePool")
+	}
+	if spec.Spot {
+		report.Dropped(api.Path(path, "spot"), "spot nodes cannot be set in GCPManagedMachinePool")
+	}
+	spec.Preemptible, spec.Spot = false, false
+
+	taints := spec.KubernetesTaints
+	spec.KubernetesTaints = nil
+
+	result, err := manifests.ToMap(spec)
+	if err != nil {
+		return nil, err
+	}
+	result["nodePoolName"] = name
+
+	if taints != nil && len(*taints) > 0 {
+		kubernetesTaints := make([]interface{}, 0, len(*taints))
+		for i, taint := range *taints {
+			if !taintEffects[taint.Effect] {
+				report.Dropped(api.Path(path, "kubernetesTaints", fmt.Sprint(i)), "taint effect %q is not supported", taint.Effect)
+				continue
+			}
+			kubernetesTaints = append(kubernetesTaints, map[string]interface{}{
+				"effect": string(taint.Effect),
+				"key":    taint.Key,
+				"value":  taint.Value,
+			})
+		}
+		result["kubernetesTaints"] = kubernetesTaints
+	}
+
+	return result, nil
+}
+
+// managedControlPlane holds fields of GCPManagedControlPlane that can be expressed in the values.
+type managedControlPlane struct {
+	ClusterName         string                 `json:"clusterName"`
+	Project             string                 `json:"project"`
+	Location            string                 `json:"location"`
+	EnableAutopilot     bool                   `json:"enableAutopilot"`
+	ControlPlaneVersion string                 `json:"controlPlaneVersion"`
+	ReleaseChannel      *api.GCPReleaseChannel `json:"releaseChannel"`
+}
+
+// readManifests rebuilds values from CAPG objects, the reverse of renderManifests.
+func readManifests(objects *api.ClusterObjects, report *api.ConversionReport) (*api.Values, error) {
+	infrastructure := objects.Infrastructure
+	clusterSpec, _, err := unstructured.NestedMap(infrastructure.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+
+	region, _, _ := unstructured.NestedString(clusterSpec, "region")
+	subnets, _, _ := unstructured.NestedSlice(clusterSpec, "network", "subnets")
+	unstructured.RemoveNestedField(clusterSpec, "network", "subnets")
+	delete(clusterSpec, "controlPlaneEndpoint")
+
+	spec := &api.GCPCloudSpec{}
+	clusterPath := manifests.ObjectPath(infrastructure, "spec")
+	if err = manifests.FromMap(clusterSpec, spec, clusterPath, report); err != nil {
+		return nil, err
+	}
+
+	for i, subnet := range subnets {
+		s, ok := subnet.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if subnetRegion, _, _ := unstructured.NestedString(s, "region"); len(subnetRegion) > 0 && subnetRegion != region {
+			report.Approximated(api.Path(clusterPath, "network", "subnets", fmt.Sprint(i), "region"), "subnet region %s is replaced with the cluster region %s", subnetRegion, region)
+		}
+		delete(s, "region")
+
+		result := api.GCPSubnet{}
+		if err = manifests.FromMap(s, &result, api.Path(clusterPath, "network", "subnets", fmt.Sprint(i)), report); err != nil {
+			return nil, err
+		}
+		spec.Subnets = append(spec.Subnets, result)
+	}
+
+	controlPlane := objects.ControlPlane
+	controlPlaneSpec, _, err := unstructured.NestedMap(controlPlane.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+	delete(controlPlaneSpec, "endpoint")
+
+	managed := &managedControlPlane{}
+	controlPlanePath := manifests.ObjectPath(controlPlane, "spec")
+	if err = manifests.FromMap(controlPlaneSpec, managed, controlPlanePath, report); err != nil {
+		return nil, err
+	}
+	spec.EnableAutopilot = managed.EnableAutopilot
+	spec.ReleaseChannel = managed.ReleaseChannel
+
+	values := &api.Values{Cluster: manifests.ReadCluster(objects.Cluster)}
+	values.Cluster.KubernetesVersion = api.KubernetesVersion(managed.ControlPlaneVersion)
+	values.Cluster.GCPCloudSpec = spec
+	if len(managed.ClusterName) > 0 && managed.ClusterName != values.Cluster.Name {
+		report.Approximated(api.Path(controlPlanePath, "clusterName"), "GKE cluster %s is named after the Cluster %s in the values", managed.ClusterName, values.Cluster.Name)
+	}
+
+	workers := api.GCPWorkers{}
+	for _, machinePool := range objects.MachinePools {
+		worker, nodePoolName, err := readWorker(machinePool, managed.ControlPlaneVersion, report)
+		if err != nil {
+			return nil, err
+		}
+
+		workers[manifests.WorkerName(values.Cluster.Name, machinePool.MachinePool, nodePoolName)] = worker
+	}
+
+	if len(workers) > 0 {
+		values.Workers.GCPWorkers = &workers
+	}
+
+	return values, nil
+}
+
+// readWorker returns worker of the machine pool and name of its GKE node pool.
+func readWorker(machinePool api.MachinePoolObjects, clusterVersion string, report *api.ConversionReport) (*api.GCPWorker, string, error) {
+	pool, infrastructure := machinePool.MachinePool, machinePool.Infrastructure
+	spec, _, err := unstructured.NestedMap(infrastructure.Object, "spec")
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodePoolName, _, _ := unstructured.NestedString(spec, "nodePoolName")
+	taints, _, _ := unstructured.NestedSlice(spec, "kubernetesTaints")
+	for _, field := range []string{"nodePoolName", "kubernetesTaints"} {
+		delete(spec, field)
+	}
+
+	worker := &api.GCPWorker{
+		Replicas:          pool.Spec.Replicas,
+		KubernetesVersion: manifests.WorkerVersion(clusterVersion, pool),
+	}
+	worker.Labels, worker.Annotations = manifests.WorkerMetadata(pool)
+
+	path := manifests.ObjectPath(infrastructure, "spec")
+	if err = manifests.FromMap(spec, &worker.Spec, path, report); err != nil {
+		return nil, "", err
+	}
+
+	if len(taints) > 0 {
+		result := make(api.GCPTaints, 0, len(taints))
+		for i, taint := range taints {
+			t, _ := taint.(map[string]interface{})
+			effect, _, _ := unstructured.NestedString(t, "effect")
+			key, _, _ := unstructured.NestedString(t, "key")
+			value, _, _ := unstructured.NestedString(t, "value")
+
+			if !taintEffects[api.GCPTaintEffect(effect)] {
+				report.Dropped(api.Path(path, "kubernetesTaints", fmt.Sprint(i)), "taint effect %q is not supported", effect)
+				continue
+			}
+			result = append(result, api.GCPTaint{Effect: api.GCPTaintEffect(effect), Key: key, Value: value})
+		}
+		worker.Spec.KubernetesTaints = &result
+	}
+
+	return worker, nodePoolName, nil
+}
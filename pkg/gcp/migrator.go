@@ -2,48 +2,135 @@ package gcp
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 )
 
+func init() {
+	api.RegisterProvider(api.ProviderRegistration{
+		Name: api.ClusterProviderGCP,
+		NewConfiguration: func() api.Validator {
+			return &api.GCPConfiguration{}
+		},
+		NewMigrator: func(ctx context.Context, config api.Validator) (api.Migrator, error) {
+			configuration, ok := config.(*api.GCPConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected gcp configuration type %T", config)
+			}
+
+			return NewGCPMigrator(ctx, configuration)
+		},
+		NewReplayMigrator: func(config api.Validator, responses []byte) (api.Migrator, error) {
+			configuration, ok := config.(*api.GCPConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected gcp configuration type %T", config)
+			}
+
+			return NewGCPReplayMigrator(configuration, responses)
+		},
+		RenderManifests:  renderManifests,
+		ReadManifests:    readManifests,
+		ControlPlaneKind: gcpManagedControlPlane.GroupKind(),
+	})
+}
+
 type Migrator struct {
 	accessor api.ClusterAccessor
 }
 
-func (this *Migrator) AddTags(tags map[string]string) error {
-	if err := this.accessor.AddClusterTags(tags); err != nil {
-		return err
+func (this *Migrator) AddTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan, err := this.PlanTags(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	return plan, this.ApplyTags(ctx, plan)
+}
+
+func (this *Migrator) PlanTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan := api.NewTagPlan(api.ClusterProviderGCP, tags)
+	if err := this.accessor.PlanClusterTags(ctx, tags, plan); err != nil {
+		return nil, err
 	}
-	if err := this.accessor.AddMachinePollsTags(tags); err != nil {
-		return err
+	if err := this.accessor.PlanMachinePoolsTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	if err := this.accessor.PlanVirtualNetworkTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (this *Migrator) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderGCP {
+		return fmt.Errorf("cannot apply %s tag plan to gcp cluster", plan.Provider)
+	}
+	return this.accessor.ApplyTags(ctx, plan)
+}
+
+func (this *Migrator) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderGCP {
+		return fmt.Errorf("cannot remove %s tag plan from gcp cluster", plan.Provider)
 	}
-	return nil
+	return this.accessor.RemoveTags(ctx, plan)
 }
 
-func (this *Migrator) Convert() (*api.Values, error) {
-	c, err := this.accessor.GetCluster()
+func (this *Migrator) Convert(ctx context.Context) (*api.Values, *api.ConversionReport, error) {
+	report := api.NewConversionReport()
+
+	c, err := this.accessor.GetCluster(ctx, report)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	w, err := this.accessor.GetWorkers(ctx, report)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	w, err := this.accessor.GetWorkers()
+	values := &api.Values{
+		APIVersion: api.Version,
+		Provider:   api.ClusterProviderGCP,
+		Type:       api.ClusterTypeManaged,
+		Cluster:    *c,
+		Workers:    *w,
+	}
+	values.SetDefaultWorkers(api.LatestDefaultWorkers())
+	values.Sort()
+	report.Sort()
+
+	return values, report, nil
+}
+
+func NewGCPMigrator(ctx context.Context, configuration *api.GCPConfiguration) (api.Migrator, error) {
+	a, err := (&ClusterAccessor{
+		configuration: configuration,
+	}).init(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &api.Values{
-		Provider: api.ClusterProviderGCP,
-		Type:     api.ClusterTypeManaged,
-		Cluster:  *c,
-		Workers:  *w,
-	}, nil
+	return &Migrator{accessor: a}, nil
 }
 
-func NewGCPMigrator(configuration *api.GCPConfiguration) (api.Migrator, error) {
+// NewGCPReplayMigrator creates migrator that converts responses recorded by Fixture without connecting to GCP.
+func NewGCPReplayMigrator(configuration *api.GCPConfiguration, responses []byte) (api.Migrator, error) {
 	a, err := (&ClusterAccessor{
 		configuration: configuration,
-		ctx:           context.Background(),
-	}).init()
+	}).initReplay(responses)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{accessor: a}, nil
+}
+
+// Fixture returns responses read by Convert, so that the conversion can be replayed.
+func (this *Migrator) Fixture() (*api.Fixture, error) {
+	recorder, ok := this.accessor.(api.Recorder)
+	if !ok {
+		return nil, fmt.Errorf("accessor does not record responses")
+	}
 
-	return &Migrator{accessor: a}, err
+	return recorder.Fixture()
 }
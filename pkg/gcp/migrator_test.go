@@ -0,0 +1,42 @@
+package gcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// fakeAccessor plans the tags of the cluster and skips its network, as ClusterAccessor does.
+type fakeAccessor struct {
+	api.ClusterAccessor
+}
+
+func (f *fakeAccessor) PlanClusterTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	return nil
+}
+
+func (f *fakeAccessor) PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	return nil
+}
+
+func (f *fakeAccessor) PlanVirtualNetworkTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
+	plan.Skip(resourceKindNetwork, "default", "GCP networks do not support labels")
+	return nil
+}
+
+func TestPlanTagsListsNetwork(t *testing.T) {
+	m := &Migrator{accessor: &fakeAccessor{}}
+
+	plan, err := m.PlanTags(context.Background(), map[string]string{"team": "plural"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Kind != resourceKindNetwork || plan.Skipped[0].ID != "default" {
+		t.Errorf("skipped resources = %+v, expected network default", plan.Skipped)
+	}
+	if plan.HasChanges() {
+		t.Errorf("plan of skipped network has changes: %+v", plan.Resources)
+	}
+}
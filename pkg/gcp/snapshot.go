@@ -0,0 +1,55 @@
+package gcp
+
+import (
+	"encoding/json"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Snapshot holds raw responses the cluster and workers are converted from.
+type Snapshot struct {
+	// Cluster is stored in protobuf JSON format.
+	Cluster     *containerpb.Cluster  `json:"-"`
+	Network     *compute.Network      `json:"network"`
+	Subnetworks []*compute.Subnetwork `json:"subnetworks"`
+	Nodes       *corev1.NodeList      `json:"nodes"`
+}
+
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	type plain Snapshot
+
+	var cluster json.RawMessage
+	if s.Cluster != nil {
+		var err error
+		if cluster, err = protojson.Marshal(s.Cluster); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(struct {
+		plain
+		Cluster json.RawMessage `json:"cluster,omitempty"`
+	}{plain: plain(s), Cluster: cluster})
+}
+
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	type plain Snapshot
+
+	aux := struct {
+		*plain
+		Cluster json.RawMessage `json:"cluster"`
+	}{plain: (*plain)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.Cluster) > 0 {
+		s.Cluster = &containerpb.Cluster{}
+		return protojson.Unmarshal(aux.Cluster, s.Cluster)
+	}
+
+	return nil
+}
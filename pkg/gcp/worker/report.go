@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"cloud.google.com/go/container/apiv1/containerpb"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+func workerPath(name string, elements ...string) string {
+	return api.Path(append([]string{"workers", "gcp", name}, elements...)...)
+}
+
+// Report records values of converted worker that were not read from the node pool.
+func (this *Workers) Report(report *api.ConversionReport, nodePool *containerpb.NodePool) {
+	report.Approximated(workerPath(nodePool.Name, "replicas"), "replicas are counted from nodes which names contain the node pool name")
+	report.Defaulted(workerPath(nodePool.Name, "isMultiAZ"), "node pool is always spread across discovered availability zones")
+
+	if len(nodePool.GetVersion()) > 0 {
+		report.Dropped(workerPath(nodePool.Name, "kubernetesVersion"), "node pool version %s is not converted, cluster version will be used", nodePool.GetVersion())
+	}
+}
@@ -17,22 +17,12 @@ type Workers struct {
 	Nodes *corev1.NodeList
 }
 
-// getDefaultAzureWorkers returns nullified list of default Azure workers.
-// It is done as we don't want to create them during migration.
-// This has to be kept in sync with bootstrap/helm/cluster-api-cluster/values.yaml.
-func getDefaultGCPWorkers() api.GCPWorkers {
-	return api.GCPWorkers{
-		"small-burst-on-demand":  nil,
-		"medium-burst-on-demand": nil,
-		"large-burst-on-demand":  nil,
-	}
-}
-
-func (this *Workers) toGCPWorkers() *api.GCPWorkers {
-	workers := getDefaultGCPWorkers()
+func (this *Workers) toGCPWorkers(report *api.ConversionReport) *api.GCPWorkers {
+	workers := api.GCPWorkers{}
 
 	for _, nodePool := range this.Cluster.NodePools {
 		workers[nodePool.Name] = this.toGCPWorker(nodePool)
+		this.Report(report, nodePool)
 	}
 
 	return &workers
@@ -105,7 +95,7 @@ func (this *Workers) additionalLabels(nodePool *containerpb.NodePool) *api.Label
 	return resources.Ptr(api.Labels(nodePool.Config.Metadata))
 }
 
-func (this *Workers) kubernetesTaints(nodePool *containerpb.NodePool) *api.Taints {
+func (this *Workers) kubernetesTaints(nodePool *containerpb.NodePool) *api.GCPTaints {
 	if nodePool == nil || nodePool.Config == nil {
 		return nil
 	}
@@ -125,36 +115,36 @@ func (this *Workers) providerIDList(nodePool *containerpb.NodePool) []string {
 	return result
 }
 
-func (this *Workers) toTaints(taints []*containerpb.NodeTaint) *api.Taints {
-	result := make([]api.Taint, 0)
+func (this *Workers) toTaints(taints []*containerpb.NodeTaint) *api.GCPTaints {
+	result := make(api.GCPTaints, 0)
 	for _, taint := range taints {
-		result = append(result, api.Taint{
+		result = append(result, api.GCPTaint{
 			Effect: this.toTaintEffect(taint.Effect),
 			Key:    taint.Key,
 			Value:  taint.Value,
 		})
 	}
 
-	return resources.Ptr(api.Taints(result))
+	return resources.Ptr(result)
 }
 
-func (this *Workers) toTaintEffect(effect containerpb.NodeTaint_Effect) api.TaintEffect {
+func (this *Workers) toTaintEffect(effect containerpb.NodeTaint_Effect) api.GCPTaintEffect {
 	switch effect {
 	case containerpb.NodeTaint_NO_SCHEDULE:
-		return "NoSchedule"
+		return api.GCPTaintEffectNoSchedule
 	case containerpb.NodeTaint_NO_EXECUTE:
-		return "NoExecute"
+		return api.GCPTaintEffectNoExecute
 	case containerpb.NodeTaint_PREFER_NO_SCHEDULE:
-		return "PreferNoSchedule"
+		return api.GCPTaintEffectPreferNoSchedule
 	default:
 		return ""
 	}
 }
 
-func (this *Workers) Convert() *api.Workers {
+func (this *Workers) Convert(report *api.ConversionReport) *api.Workers {
 	return &api.Workers{
 		WorkersSpec: api.WorkersSpec{
-			GCPWorkers: this.toGCPWorkers(),
+			GCPWorkers: this.toGCPWorkers(report),
 		},
 	}
 }
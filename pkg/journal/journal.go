@@ -0,0 +1,76 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// Journal stores tag plan in a local file. It is recorded after every resource is tagged or reverted,
+// so that the file always lists applied tags together with their previous values.
+type Journal struct {
+	path string
+}
+
+func New(path string) *Journal {
+	return &Journal{path: path}
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Load reads the plan from the journal. It returns nil plan if the journal does not exist.
+func (j *Journal) Load() (*api.TagPlan, error) {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &api.TagPlan{}
+	if err = json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("invalid tag journal %s: %w", j.path, err)
+	}
+
+	return plan, nil
+}
+
+// Record writes the plan to the journal. The file is replaced atomically, so that it is never left half-written.
+func (j *Journal) Record(plan *api.TagPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), j.path)
+}
+
+// Remove deletes the journal. It does not fail if the journal does not exist.
+func (j *Journal) Remove() error {
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package kind
 
 import (
+	"context"
+
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 )
 
@@ -8,26 +10,38 @@ type ClusterAccessor struct {
 	configuration *api.KindConfiguration
 }
 
-func (this *ClusterAccessor) AddClusterTags(tags map[string]string) error {
+func (this *ClusterAccessor) PlanClusterTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (this *ClusterAccessor) AddMachinePollsTags(tags map[string]string) error {
+func (this *ClusterAccessor) PlanMachinePoolsTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (this *ClusterAccessor) AddVirtualNetworkTags(tags map[string]string) error {
+func (this *ClusterAccessor) PlanVirtualNetworkTags(ctx context.Context, tags map[string]string, plan *api.TagPlan) error {
 	return nil
 }
 
-func (this *ClusterAccessor) GetCluster() (*api.Cluster, error) {
+func (this *ClusterAccessor) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	return nil
+}
+
+func (this *ClusterAccessor) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	return nil
+}
+
+func (this *ClusterAccessor) GetCluster(ctx context.Context, report *api.ConversionReport) (*api.Cluster, error) {
 	return nil, nil
 }
 
-func (this *ClusterAccessor) GetWorkers() (*api.Workers, error) {
+func (this *ClusterAccessor) GetWorkers(ctx context.Context, report *api.ConversionReport) (*api.Workers, error) {
 	return nil, nil
 }
 
-func (this *ClusterAccessor) init() (api.ClusterAccessor, error) {
+func (this *ClusterAccessor) Fixture() (*api.Fixture, error) {
+	return api.NewFixture(api.ClusterProviderKind, this.configuration, struct{}{})
+}
+
+func (this *ClusterAccessor) init(ctx context.Context) (api.ClusterAccessor, error) {
 	return this, nil
 }
@@ -1,30 +1,92 @@
 package kind
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
 )
 
+func init() {
+	api.RegisterProvider(api.ProviderRegistration{
+		Name: api.ClusterProviderKind,
+		NewConfiguration: func() api.Validator {
+			return &api.KindConfiguration{}
+		},
+		NewMigrator: func(ctx context.Context, config api.Validator) (api.Migrator, error) {
+			configuration, ok := config.(*api.KindConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected kind configuration type %T", config)
+			}
+
+			return NewKindMigrator(ctx, configuration)
+		},
+		NewReplayMigrator: func(config api.Validator, responses []byte) (api.Migrator, error) {
+			configuration, ok := config.(*api.KindConfiguration)
+			if !ok {
+				return nil, fmt.Errorf("unexpected kind configuration type %T", config)
+			}
+
+			// Kind migrator does not read anything, so there is nothing to replay.
+			return NewKindMigrator(context.Background(), configuration)
+		},
+	})
+}
+
 type Migrator struct {
 	accessor api.ClusterAccessor
 }
 
-func (m Migrator) AddTags(tags map[string]string) error {
-	return nil
+func (m Migrator) AddTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan, err := m.PlanTags(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	return plan, m.ApplyTags(ctx, plan)
+}
+
+func (m Migrator) PlanTags(ctx context.Context, tags map[string]string) (*api.TagPlan, error) {
+	plan := api.NewTagPlan(api.ClusterProviderKind, tags)
+	if err := m.accessor.PlanClusterTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	if err := m.accessor.PlanMachinePoolsTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	if err := m.accessor.PlanVirtualNetworkTags(ctx, tags, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (m Migrator) ApplyTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderKind {
+		return fmt.Errorf("cannot apply %s tag plan to kind cluster", plan.Provider)
+	}
+	return m.accessor.ApplyTags(ctx, plan)
 }
 
-func (m Migrator) Convert() (*api.Values, error) {
+func (m Migrator) RemoveTags(ctx context.Context, plan *api.TagPlan) error {
+	if plan.Provider != api.ClusterProviderKind {
+		return fmt.Errorf("cannot remove %s tag plan from kind cluster", plan.Provider)
+	}
+	return m.accessor.RemoveTags(ctx, plan)
+}
+
+func (m Migrator) Convert(ctx context.Context) (*api.Values, *api.ConversionReport, error) {
 	return &api.Values{
-		Provider: api.ClusterProviderKind,
-		Type:     api.ClusterTypeManaged,
-		Cluster:  api.Cluster{},
-		Workers:  api.Workers{},
-	}, nil
+		APIVersion: api.Version,
+		Provider:   api.ClusterProviderKind,
+		Type:       api.ClusterTypeManaged,
+		Cluster:    api.Cluster{},
+		Workers:    api.Workers{},
+	}, api.NewConversionReport(), nil
 }
 
-func NewKindMigrator(configuration *api.KindConfiguration) (api.Migrator, error) {
+func NewKindMigrator(ctx context.Context, configuration *api.KindConfiguration) (api.Migrator, error) {
 	a, err := (&ClusterAccessor{
 		configuration: configuration,
-	}).init()
+	}).init(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -32,3 +94,11 @@ func NewKindMigrator(configuration *api.KindConfiguration) (api.Migrator, error)
 		accessor: a,
 	}, nil
 }
+
+func (m Migrator) Fixture() (*api.Fixture, error) {
+	recorder, ok := m.accessor.(api.Recorder)
+	if !ok {
+		return nil, fmt.Errorf("accessor does not record responses")
+	}
+	return recorder.Fixture()
+}
@@ -0,0 +1,269 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+)
+
+// kubernetesVersion matches versions with or without the patch part, i.e. v1.27 or 1.27.3-gke.100.
+var kubernetesVersion = regexp.MustCompile(`^v?(\d+)\.(\d+)(\.\d+)?(.*)$`)
+
+// Renderer returns Cluster API objects built from the values, i.e. Render or RenderTopology.
+type Renderer func(values *api.Values, report *api.ConversionReport) ([]*unstructured.Unstructured, error)
+
+// Render returns Cluster API objects that adopt the existing cluster, as an alternative to the chart values.
+// Objects are ordered so that the referenced ones come first.
+func Render(values *api.Values, report *api.ConversionReport) ([]*unstructured.Unstructured, error) {
+	registration, ok := api.LookupProvider(values.Provider)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider %q", values.Provider)
+	}
+
+	if registration.RenderManifests == nil {
+		return nil, fmt.Errorf("provider %s does not support rendering Cluster API manifests", values.Provider)
+	}
+
+	objects, err := registration.RenderManifests(values, report)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, object := range objects {
+		u, err := toUnstructured(object)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, u)
+	}
+
+	return result, nil
+}
+
+// Unmanaged returns paths of the values that Cluster API objects rendered from them cannot hold, so that they
+// are not reported as a drift of the cluster from its objects.
+func Unmanaged(values *api.Values) ([]string, error) {
+	report := api.NewConversionReport()
+	if _, err := Render(values, report); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for _, warning := range report.Warnings {
+		if warning.Kind == api.ConversionWarningDropped {
+			result = append(result, warning.Path)
+		}
+	}
+
+	return result, nil
+}
+
+// toUnstructured converts typed object and drops fields that are only set by the controllers.
+func toUnstructured(object runtime.Object) (*unstructured.Unstructured, error) {
+	u, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return nil, err
+		}
+
+		u = &unstructured.Unstructured{Object: content}
+	}
+
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	if host, _, _ := unstructured.NestedString(u.Object, "spec", "controlPlaneEndpoint", "host"); len(host) == 0 {
+		unstructured.RemoveNestedField(u.Object, "spec", "controlPlaneEndpoint")
+	}
+
+	return u, nil
+}
+
+// YAML joins objects into a multi-document YAML that can be passed to kubectl apply.
+func YAML(objects []*unstructured.Unstructured) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	for i, object := range objects {
+		data, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buffer.WriteString("---\n")
+		}
+		buffer.Write(data)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// JSON wraps objects in a v1 List.
+func JSON(objects []*unstructured.Unstructured) ([]byte, error) {
+	items := make([]interface{}, 0, len(objects))
+	for _, object := range objects {
+		items = append(items, object.Object)
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Reference returns reference to the object with the given kind and name.
+func Reference(gvk schema.GroupVersionKind, name string) *corev1.ObjectReference {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Name: name}
+}
+
+// NewCluster returns Cluster that references the control plane and the infrastructure cluster.
+func NewCluster(cluster api.Cluster, labels map[string]string, controlPlane, infrastructure *corev1.ObjectReference) *clusterv1.Cluster {
+	result := &clusterv1.Cluster{
+		TypeMeta:   TypeMeta(clusterv1.GroupVersion.WithKind("Cluster")),
+		ObjectMeta: metav1.ObjectMeta{Name: cluster.Name, Labels: labels},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef:   controlPlane,
+			InfrastructureRef: infrastructure,
+		},
+	}
+
+	if len(cluster.PodCIDRBlocks) > 0 || len(cluster.ServiceCIDRBlocks) > 0 {
+		result.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{}
+		if len(cluster.PodCIDRBlocks) > 0 {
+			result.Spec.ClusterNetwork.Pods = &clusterv1.NetworkRanges{CIDRBlocks: cluster.PodCIDRBlocks}
+		}
+		if len(cluster.ServiceCIDRBlocks) > 0 {
+			result.Spec.ClusterNetwork.Services = &clusterv1.NetworkRanges{CIDRBlocks: cluster.ServiceCIDRBlocks}
+		}
+	}
+
+	return result
+}
+
+// NewMachinePool returns MachinePool of the cluster that references the managed machine pool of the provider.
+// Managed machine pools are bootstrapped by the cloud provider, so no bootstrap config is referenced.
+func NewMachinePool(clusterName, name string, replicas *int32, version string, labels, annotations map[string]string, infrastructure *corev1.ObjectReference) *expv1.MachinePool {
+	result := &expv1.MachinePool{
+		TypeMeta: TypeMeta(expv1.GroupVersion.WithKind("MachinePool")),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: expv1.MachinePoolSpec{
+			ClusterName: clusterName,
+			Replicas:    replicas,
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					ClusterName:       clusterName,
+					Bootstrap:         clusterv1.Bootstrap{DataSecretName: resources.Ptr("")},
+					InfrastructureRef: *infrastructure,
+				},
+			},
+		},
+	}
+
+	if len(version) > 0 {
+		result.Spec.Template.Spec.Version = &version
+	}
+
+	return result
+}
+
+// MachinePoolVersion returns version in the format required by MachinePool, i.e. v1.27.0 for 1.27.
+// Provider specific suffixes are dropped. The report gets a warning if the version had to be changed.
+func MachinePoolVersion(version, path string, report *api.ConversionReport) string {
+	match := kubernetesVersion.FindStringSubmatch(version)
+	if match == nil {
+		if len(version) > 0 {
+			report.Dropped(path, "version %q is not a valid Kubernetes version", version)
+		}
+
+		return ""
+	}
+
+	patch := match[3]
+	if len(patch) == 0 {
+		patch = ".0"
+	}
+
+	result := fmt.Sprintf("v%s.%s%s", match[1], match[2], patch)
+	if len(match[3]) == 0 || len(match[4]) > 0 {
+		report.Approximated(path, "machine pool version %s is derived from %s", result, version)
+	}
+
+	return result
+}
+
+// TypeMeta returns type meta of the object with the given kind, typed objects do not set it themselves.
+func TypeMeta(gvk schema.GroupVersionKind) metav1.TypeMeta {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return metav1.TypeMeta{APIVersion: apiVersion, Kind: kind}
+}
+
+// NewObject returns unstructured object for providers whose API types are not vendored.
+func NewObject(gvk schema.GroupVersionKind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	if spec != nil {
+		u.Object["spec"] = spec
+	}
+
+	return u
+}
+
+// ToMap converts value to a map using its JSON tags, which match fields of the upstream API types.
+// Null values are dropped, so that optional fields the values do not set are left out.
+func ToMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	pruneNulls(result)
+	return result, nil
+}
+
+func pruneNulls(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+				continue
+			}
+			pruneNulls(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			pruneNulls(item)
+		}
+	}
+}
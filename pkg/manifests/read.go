@@ -0,0 +1,294 @@
+package manifests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+)
+
+// reader gets objects referenced by the Cluster, resolving their resources with the discovery API.
+type reader struct {
+	client    dynamic.Interface
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	namespace string
+}
+
+// Read returns Cluster with its control plane, infrastructure cluster and machine pools from the management cluster.
+func Read(ctx context.Context, config *rest.Config, namespace, name string) (*api.ClusterObjects, error) {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{
+		client:    client,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		namespace: namespace,
+	}
+
+	object, err := r.get(ctx, clusterv1.GroupVersion.WithKind("Cluster"), namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, cluster); err != nil {
+		return nil, err
+	}
+
+	if cluster.Spec.ControlPlaneRef == nil || cluster.Spec.InfrastructureRef == nil {
+		return nil, fmt.Errorf("cluster %s/%s does not reference its control plane and infrastructure yet", namespace, name)
+	}
+
+	result := &api.ClusterObjects{Cluster: cluster}
+	if result.ControlPlane, err = r.reference(ctx, cluster.Spec.ControlPlaneRef); err != nil {
+		return nil, err
+	}
+	if result.Infrastructure, err = r.reference(ctx, cluster.Spec.InfrastructureRef); err != nil {
+		return nil, err
+	}
+
+	if result.MachinePools, err = r.machinePools(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// machinePools lists machine pools of the cluster sorted by name, with the managed machine pools they reference.
+func (r *reader) machinePools(ctx context.Context, clusterName string) ([]api.MachinePoolObjects, error) {
+	mapping, err := r.mapper.RESTMapping(expv1.GroupVersion.WithKind("MachinePool").GroupKind(), expv1.GroupVersion.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// MachinePool feature of Cluster API is not enabled, the cluster cannot have any.
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	list, err := r.client.Resource(mapping.Resource).Namespace(r.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", clusterv1.ClusterNameLabel, clusterName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+
+	result := make([]api.MachinePoolObjects, 0, len(list.Items))
+	for _, item := range list.Items {
+		pool := &expv1.MachinePool{}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, pool); err != nil {
+			return nil, err
+		}
+
+		infrastructure, err := r.reference(ctx, &pool.Spec.Template.Spec.InfrastructureRef)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, api.MachinePoolObjects{MachinePool: pool, Infrastructure: infrastructure})
+	}
+
+	return result, nil
+}
+
+func (r *reader) reference(ctx context.Context, reference *corev1.ObjectReference) (*unstructured.Unstructured, error) {
+	namespace := reference.Namespace
+	if len(namespace) == 0 {
+		namespace = r.namespace
+	}
+
+	return r.get(ctx, reference.GroupVersionKind(), namespace, reference.Name)
+}
+
+func (r *reader) get(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not served by the management cluster: %w", gvk.Kind, err)
+	}
+
+	object, err := r.client.Resource(mapping.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%s %s/%s not found", gvk.Kind, namespace, name)
+	}
+
+	return object, err
+}
+
+// Values rebuilds values from the objects with the reader of the provider that owns the control plane.
+func Values(objects *api.ClusterObjects, report *api.ConversionReport) (*api.Values, error) {
+	kind := objects.ControlPlane.GroupVersionKind().GroupKind()
+	registration, ok := api.LookupProviderByControlPlane(kind)
+	if !ok {
+		return nil, fmt.Errorf("no provider reads clusters with %s control plane", kind)
+	}
+
+	values, err := registration.ReadManifests(objects, report)
+	if err != nil {
+		return nil, err
+	}
+
+	values.APIVersion = api.Version
+	values.Provider = registration.Name
+	values.Type = api.ClusterTypeManaged
+	values.Sort()
+	report.Sort()
+	return values, nil
+}
+
+// ReadCluster returns cluster values shared by all providers, the reverse of NewCluster.
+func ReadCluster(cluster *clusterv1.Cluster) api.Cluster {
+	result := api.Cluster{Name: cluster.Name}
+	if network := cluster.Spec.ClusterNetwork; network != nil {
+		if network.Pods != nil {
+			result.PodCIDRBlocks = network.Pods.CIDRBlocks
+		}
+		if network.Services != nil {
+			result.ServiceCIDRBlocks = network.Services.CIDRBlocks
+		}
+	}
+
+	return result
+}
+
+// WorkerName returns name of the worker the machine pool was rendered for. The name of the node pool
+// in the cloud is preferred, as NewMachinePool prefixes it with the cluster name.
+func WorkerName(clusterName string, pool *expv1.MachinePool, nodePoolName string) string {
+	if len(nodePoolName) > 0 {
+		return nodePoolName
+	}
+
+	return strings.TrimPrefix(pool.Name, clusterName+"-")
+}
+
+// WorkerVersion returns version of the machine pool in the values format if it differs from the cluster version.
+func WorkerVersion(clusterVersion string, pool *expv1.MachinePool) *string {
+	version := pool.Spec.Template.Spec.Version
+	if version == nil || len(*version) == 0 {
+		return nil
+	}
+
+	if *version == MachinePoolVersion(clusterVersion, "", nil) {
+		return nil
+	}
+
+	return resources.Ptr(api.KubernetesVersion(*version))
+}
+
+// FromMap decodes spec of an unstructured object into the values type, the reverse of ToMap.
+// Fields the type does not have are added to the report as dropped under the given path.
+func FromMap(spec map[string]interface{}, target interface{}, path string, report *api.ConversionReport) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(data, target); err != nil {
+		return err
+	}
+
+	known := jsonFields(reflect.TypeOf(target))
+	fields := make([]string, 0)
+	for field := range spec {
+		if !known[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		report.Dropped(api.Path(path, field), "field cannot be expressed in the values")
+	}
+
+	return nil
+}
+
+// jsonFields returns JSON names of the struct fields, including the ones of inlined structs.
+func jsonFields(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	result := map[string]bool{}
+	if t.Kind() != reflect.Struct {
+		return result
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if (field.Anonymous && len(name) == 0) || options == "inline" {
+			for inlined := range jsonFields(field.Type) {
+				result[inlined] = true
+			}
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		result[name] = true
+	}
+
+	return result
+}
+
+// ObjectPath returns path of the object field used in the report, i.e. AzureManagedControlPlane/demo.spec.sku.
+func ObjectPath(object *unstructured.Unstructured, fields ...string) string {
+	return api.Path(append([]string{fmt.Sprintf("%s/%s", object.GetKind(), object.GetName())}, fields...)...)
+}
+
+// WorkerMetadata returns labels and annotations of the machine pool without the ones set by Cluster API and kubectl.
+func WorkerMetadata(pool *expv1.MachinePool) (labels, annotations map[string]string) {
+	return UserMetadata(pool.Labels), UserMetadata(pool.Annotations)
+}
+
+// UserMetadata returns labels or annotations without the ones set by Cluster API and kubectl.
+func UserMetadata(metadata map[string]string) map[string]string {
+	var result map[string]string
+	for key, value := range metadata {
+		if strings.HasPrefix(key, clusterv1.GroupVersion.Group+"/") || strings.HasPrefix(key, "kubectl.kubernetes.io/") {
+			continue
+		}
+
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[key] = value
+	}
+
+	return result
+}
@@ -0,0 +1,374 @@
+package manifests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+)
+
+// templateSuffix is appended to kinds of the rendered objects to get kinds of their templates.
+const templateSuffix = "Template"
+
+// RenderTopology returns ClusterClass with its templates and Cluster that uses the class through its topology.
+// Machine pools whose specs differ only in the pool variables of the provider share a machine pool class,
+// the differing fields are set with per-pool variable overrides.
+func RenderTopology(values *api.Values, report *api.ConversionReport) ([]*unstructured.Unstructured, error) {
+	registration, ok := api.LookupProvider(values.Provider)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider %q", values.Provider)
+	}
+
+	support := registration.ClusterClass
+	if support == nil {
+		return nil, fmt.Errorf("provider %s does not support ClusterClass, its Cluster API provider has no templates of the managed objects, use manifests mode instead", values.Provider)
+	}
+
+	objects, err := Render(values, report)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &topologyBuilder{support: support, objects: map[string]*unstructured.Unstructured{}}
+	for _, object := range objects {
+		b.objects[objectKey(object.GetKind(), object.GetName())] = object
+	}
+
+	for _, object := range objects {
+		if object.GroupVersionKind() == clusterv1.GroupVersion.WithKind("Cluster") {
+			return b.build(object, objects, values, report)
+		}
+	}
+
+	return nil, fmt.Errorf("provider %s did not render Cluster", values.Provider)
+}
+
+type topologyBuilder struct {
+	support   *api.ClusterClassSupport
+	objects   map[string]*unstructured.Unstructured
+	class     *clusterv1.ClusterClass
+	templates []*unstructured.Unstructured
+}
+
+// poolClass is a machine pool class with the pools that use it and values of the pool variables they override.
+type poolClass struct {
+	name      string
+	template  *unstructured.Unstructured
+	pools     []*expv1.MachinePool
+	overrides [][]clusterv1.ClusterVariable
+	// fields are the pool variable fields set by any of the pools, with a value of the first one.
+	fields map[string]interface{}
+}
+
+func (b *topologyBuilder) build(clusterObject *unstructured.Unstructured, objects []*unstructured.Unstructured, values *api.Values, report *api.ConversionReport) ([]*unstructured.Unstructured, error) {
+	cluster := &clusterv1.Cluster{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(clusterObject.Object, cluster); err != nil {
+		return nil, err
+	}
+
+	name := cluster.Name
+	b.class = &clusterv1.ClusterClass{
+		TypeMeta:   TypeMeta(clusterv1.GroupVersion.WithKind("ClusterClass")),
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+
+	infrastructure, err := b.object(cluster.Spec.InfrastructureRef)
+	if err != nil {
+		return nil, err
+	}
+	b.class.Spec.Infrastructure.Ref = b.addTemplate(newTemplate(infrastructure, name))
+
+	controlPlane, err := b.object(cluster.Spec.ControlPlaneRef)
+	if err != nil {
+		return nil, err
+	}
+	controlPlaneTemplate := newTemplate(controlPlane, fmt.Sprintf("%s-control-plane", name))
+	variables := b.clusterVariables(controlPlaneTemplate)
+	b.class.Spec.ControlPlane.Ref = b.addTemplate(controlPlaneTemplate)
+
+	pools, err := b.poolClasses(name, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &clusterv1.Topology{
+		Class:     name,
+		Version:   MachinePoolVersion(values.Cluster.KubernetesVersion, "cluster.kubernetesVersion", nil),
+		Variables: variables,
+	}
+	if len(topology.Version) == 0 {
+		report.Dropped("cluster.kubernetesVersion", "version is required by the Cluster topology and has to be added")
+	}
+
+	if len(pools) > 0 {
+		topology.Workers = &clusterv1.WorkersTopology{}
+	}
+	for _, class := range pools {
+		if err = b.addPoolClass(name, class); err != nil {
+			return nil, err
+		}
+
+		for i, pool := range class.pools {
+			machinePool := clusterv1.MachinePoolTopology{
+				Metadata: clusterv1.ObjectMeta{Labels: pool.Labels, Annotations: pool.Annotations},
+				Class:    class.name,
+				Name:     strings.TrimPrefix(pool.Name, name+"-"),
+				Replicas: pool.Spec.Replicas,
+			}
+			if len(class.overrides[i]) > 0 {
+				machinePool.Variables = &clusterv1.MachinePoolVariables{Overrides: class.overrides[i]}
+			}
+
+			topology.Workers.MachinePools = append(topology.Workers.MachinePools, machinePool)
+		}
+	}
+
+	cluster.Spec.ControlPlaneRef = nil
+	cluster.Spec.InfrastructureRef = nil
+	cluster.Spec.Topology = topology
+
+	class, err := toUnstructured(b.class)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterObject, err = toUnstructured(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append([]*unstructured.Unstructured{class}, b.templates...)
+	return append(result, clusterObject), nil
+}
+
+// clusterVariables replaces cluster variable fields of the control plane template with variables
+// and returns their values for the Cluster topology.
+func (b *topologyBuilder) clusterVariables(template *unstructured.Unstructured) []clusterv1.ClusterVariable {
+	spec, _, _ := unstructured.NestedMap(template.Object, "spec", "template", "spec")
+
+	result := make([]clusterv1.ClusterVariable, 0, len(b.support.ClusterVariables))
+	for _, field := range b.support.ClusterVariables {
+		value, ok := spec[field]
+		if !ok {
+			continue
+		}
+
+		unstructured.RemoveNestedField(template.Object, "spec", "template", "spec", field)
+		b.addVariable(field, value, true, clusterv1.PatchSelector{
+			APIVersion:     template.GetAPIVersion(),
+			Kind:           template.GetKind(),
+			MatchResources: clusterv1.PatchSelectorMatch{ControlPlane: true},
+		}, field)
+
+		result = append(result, clusterv1.ClusterVariable{Name: field, Value: jsonValue(value)})
+	}
+
+	return result
+}
+
+// poolClasses groups machine pools by specs of their infrastructure objects without the pool variables.
+func (b *topologyBuilder) poolClasses(clusterName string, objects []*unstructured.Unstructured) ([]*poolClass, error) {
+	var result []*poolClass
+	classes := map[string]*poolClass{}
+
+	for _, object := range objects {
+		if object.GroupVersionKind() != expv1.GroupVersion.WithKind("MachinePool") {
+			continue
+		}
+
+		pool := &expv1.MachinePool{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, pool); err != nil {
+			return nil, err
+		}
+
+		infrastructure, err := b.object(&pool.Spec.Template.Spec.InfrastructureRef)
+		if err != nil {
+			return nil, err
+		}
+
+		template := newTemplate(infrastructure, "")
+		values := map[string]interface{}{}
+		var overrides []clusterv1.ClusterVariable
+		for _, field := range b.support.PoolVariables {
+			value, ok, _ := unstructured.NestedFieldCopy(template.Object, "spec", "template", "spec", field)
+			if !ok {
+				continue
+			}
+
+			unstructured.RemoveNestedField(template.Object, "spec", "template", "spec", field)
+			overrides = append(overrides, clusterv1.ClusterVariable{Name: poolVariable(field), Value: jsonValue(value)})
+			values[field] = value
+		}
+
+		key, err := json.Marshal(template.Object["spec"])
+		if err != nil {
+			return nil, err
+		}
+
+		class, ok := classes[string(key)]
+		if !ok {
+			class = &poolClass{name: strings.TrimPrefix(pool.Name, clusterName+"-"), template: template, fields: map[string]interface{}{}}
+			classes[string(key)] = class
+			result = append(result, class)
+		}
+
+		for field, value := range values {
+			if _, ok := class.fields[field]; !ok {
+				class.fields[field] = value
+			}
+		}
+		class.pools = append(class.pools, pool)
+		class.overrides = append(class.overrides, overrides)
+	}
+
+	return result, nil
+}
+
+// addPoolClass adds machine pool class with its templates and patches that set the pool variables.
+func (b *topologyBuilder) addPoolClass(clusterName string, class *poolClass) error {
+	templateName := fmt.Sprintf("%s-%s", clusterName, class.name)
+	class.template.SetName(templateName)
+
+	bootstrap, err := toUnstructured(b.support.BootstrapTemplate(templateName))
+	if err != nil {
+		return err
+	}
+
+	b.class.Spec.Workers.MachinePools = append(b.class.Spec.Workers.MachinePools, clusterv1.MachinePoolClass{
+		Class: class.name,
+		Template: clusterv1.MachinePoolClassTemplate{
+			Bootstrap:      clusterv1.LocalObjectTemplate{Ref: b.addTemplate(bootstrap)},
+			Infrastructure: clusterv1.LocalObjectTemplate{Ref: b.addTemplate(class.template)},
+		},
+	})
+
+	for _, field := range b.support.PoolVariables {
+		value, ok := class.fields[field]
+		if !ok {
+			continue
+		}
+
+		b.addVariable(poolVariable(field), value, false, clusterv1.PatchSelector{
+			APIVersion: class.template.GetAPIVersion(),
+			Kind:       class.template.GetKind(),
+			MatchResources: clusterv1.PatchSelectorMatch{
+				MachinePoolClass: &clusterv1.PatchSelectorMatchMachinePoolClass{Names: []string{class.name}},
+			},
+		}, field)
+	}
+
+	return nil
+}
+
+// addVariable defines variable with schema derived from its value and a patch that sets the field from it.
+// Variable shared by several machine pool classes gets one patch definition per class.
+func (b *topologyBuilder) addVariable(name string, value interface{}, required bool, selector clusterv1.PatchSelector, field string) {
+	definition := clusterv1.PatchDefinition{
+		Selector: selector,
+		JSONPatches: []clusterv1.JSONPatch{{
+			Op:        "add",
+			Path:      "/spec/template/spec/" + field,
+			ValueFrom: &clusterv1.JSONPatchValue{Variable: &name},
+		}},
+	}
+
+	for i := range b.class.Spec.Patches {
+		if b.class.Spec.Patches[i].Name == name {
+			b.class.Spec.Patches[i].Definitions = append(b.class.Spec.Patches[i].Definitions, definition)
+			return
+		}
+	}
+
+	b.class.Spec.Variables = append(b.class.Spec.Variables, clusterv1.ClusterClassVariable{
+		Name:     name,
+		Required: required,
+		Schema:   clusterv1.VariableSchema{OpenAPIV3Schema: schemaOf(value)},
+	})
+	b.class.Spec.Patches = append(b.class.Spec.Patches, clusterv1.ClusterClassPatch{
+		Name:        name,
+		Definitions: []clusterv1.PatchDefinition{definition},
+	})
+}
+
+// addTemplate adds template to the output and returns reference to it.
+func (b *topologyBuilder) addTemplate(template *unstructured.Unstructured) *corev1.ObjectReference {
+	b.templates = append(b.templates, template)
+	return Reference(template.GroupVersionKind(), template.GetName())
+}
+
+func (b *topologyBuilder) object(reference *corev1.ObjectReference) (*unstructured.Unstructured, error) {
+	if reference == nil {
+		return nil, fmt.Errorf("cluster does not reference all of its objects")
+	}
+
+	object, ok := b.objects[objectKey(reference.Kind, reference.Name)]
+	if !ok {
+		return nil, fmt.Errorf("%s %s is referenced but was not rendered", reference.Kind, reference.Name)
+	}
+
+	return object, nil
+}
+
+// newTemplate returns template of the object, whose spec is nested in spec.template.spec.
+func newTemplate(object *unstructured.Unstructured, name string) *unstructured.Unstructured {
+	spec, ok := object.Object["spec"]
+	if !ok {
+		spec = map[string]interface{}{}
+	}
+
+	template := NewObject(object.GroupVersionKind(), name, map[string]interface{}{
+		"template": map[string]interface{}{"spec": runtime.DeepCopyJSONValue(spec)},
+	})
+	template.SetKind(object.GetKind() + templateSuffix)
+	return template
+}
+
+func objectKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// poolVariable returns name of the variable that sets the machine pool field, the prefix distinguishes it
+// from the cluster variables.
+func poolVariable(field string) string {
+	return "machinePool" + strings.ToUpper(field[:1]) + field[1:]
+}
+
+func jsonValue(value interface{}) apiextensionsv1.JSON {
+	data, _ := json.Marshal(value)
+	return apiextensionsv1.JSON{Raw: data}
+}
+
+// schemaOf returns schema of the value. Objects and arrays keep unknown fields, as their schema is defined
+// by the provider API the patches are applied to.
+func schemaOf(value interface{}) clusterv1.JSONSchemaProps {
+	switch v := value.(type) {
+	case string:
+		return clusterv1.JSONSchemaProps{Type: "string"}
+	case bool:
+		return clusterv1.JSONSchemaProps{Type: "boolean"}
+	case int64, int32, int:
+		return clusterv1.JSONSchemaProps{Type: "integer"}
+	case float64:
+		if v == float64(int64(v)) {
+			return clusterv1.JSONSchemaProps{Type: "integer"}
+		}
+		return clusterv1.JSONSchemaProps{Type: "number"}
+	case []interface{}:
+		items := clusterv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: true}
+		if len(v) > 0 {
+			items = schemaOf(v[0])
+		}
+		return clusterv1.JSONSchemaProps{Type: "array", Items: &items}
+	default:
+		return clusterv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: true}
+	}
+}
@@ -1,27 +1,161 @@
 package migrator
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sigs.k8s.io/yaml"
 
 	"github.com/pluralsh/cluster-api-migration/pkg/api"
-	"github.com/pluralsh/cluster-api-migration/pkg/aws"
-	"github.com/pluralsh/cluster-api-migration/pkg/azure"
-	"github.com/pluralsh/cluster-api-migration/pkg/gcp"
-	"github.com/pluralsh/cluster-api-migration/pkg/kind"
+	"github.com/pluralsh/cluster-api-migration/pkg/schema"
+
+	// Older versions of values register themselves in init.
+	_ "github.com/pluralsh/cluster-api-migration/pkg/api/v1alpha1"
+	// Built-in providers register themselves in init.
+	_ "github.com/pluralsh/cluster-api-migration/pkg/aws"
+	_ "github.com/pluralsh/cluster-api-migration/pkg/azure"
+	_ "github.com/pluralsh/cluster-api-migration/pkg/gcp"
+	_ "github.com/pluralsh/cluster-api-migration/pkg/kind"
 )
 
-func NewMigrator(provider api.ClusterProvider, config *api.Configuration) (api.Migrator, error) {
-	switch provider {
-	case api.ClusterProviderGCP:
-		return gcp.NewGCPMigrator(config.GCPConfiguration)
-	case api.ClusterProviderAzure:
-		return azure.NewAzureMigrator(config.AzureConfiguration)
-	case api.ClusterProviderAWS:
-		return aws.NewAWSMigrator(config.AWSConfiguration)
-	case api.ClusterProviderKind:
-		return kind.NewKindMigrator(config.KindConfiguration)
-	default:
-		return nil, fmt.Errorf("unsupported provider")
+// NewMigrator validates configuration of the given provider and creates migrator using its registered factory.
+// Providers other than the built-in ones have to be registered with api.RegisterProvider first.
+func NewMigrator(ctx context.Context, provider api.ClusterProvider, config *api.Configuration) (api.Migrator, error) {
+	if config == nil {
+		return nil, &api.InvalidConfigError{Err: fmt.Errorf("configuration cannot be empty")}
+	}
+
+	registration, ok := api.LookupProvider(provider)
+	if !ok {
+		return nil, &api.InvalidConfigError{Err: fmt.Errorf("unsupported provider %q", provider)}
+	}
+
+	providerConfig, err := config.ProviderConfiguration(provider)
+	if err != nil {
+		return nil, &api.InvalidConfigError{Err: err}
+	}
+
+	if err = providerConfig.Validate(); err != nil {
+		return nil, &api.InvalidConfigError{Err: err}
+	}
+
+	return registration.NewMigrator(ctx, providerConfig)
+}
+
+// Providers returns names of all registered providers, including the built-in ones.
+func Providers() []api.ClusterProvider {
+	return api.Providers()
+}
+
+// NewReplayMigrator creates migrator that converts responses recorded in the fixture
+// without connecting to the cloud provider. It can only be used to convert the cluster.
+func NewReplayMigrator(fixture *api.Fixture) (api.Migrator, error) {
+	registration, ok := api.LookupProvider(fixture.Provider)
+	if !ok {
+		return nil, &api.InvalidConfigError{Err: fmt.Errorf("unsupported provider %q", fixture.Provider)}
+	}
+
+	if registration.NewReplayMigrator == nil {
+		return nil, fmt.Errorf("provider %s cannot replay recorded responses", fixture.Provider)
+	}
+
+	providerConfig := registration.NewConfiguration()
+	if err := json.Unmarshal(fixture.Configuration, providerConfig); err != nil {
+		return nil, &api.InvalidConfigError{Err: fmt.Errorf("invalid %s configuration in fixture: %w", fixture.Provider, err)}
+	}
+
+	return registration.NewReplayMigrator(providerConfig, fixture.Responses)
+}
+
+// Record returns responses the migrator read from the cloud provider, so that they can be replayed with NewReplayMigrator.
+func Record(m api.Migrator) (*api.Fixture, error) {
+	recorder, ok := m.(api.Recorder)
+	if !ok {
+		return nil, fmt.Errorf("migrator does not record responses")
+	}
+
+	return recorder.Fixture()
+}
+
+// ReadFixture reads fixture written by WriteFixture.
+func ReadFixture(path string) (*api.Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &api.Fixture{}
+	if err = json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+func WriteFixture(path string, fixture *api.Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// ReadValues reads values written by convert, in YAML or JSON. Values of older versions are converted to the current one.
+// Keys that are not part of the values, i.e. other settings of the chart values.yaml, are ignored.
+func ReadValues(path string) (*api.Values, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, _, err := api.DecodeValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// ReadDefaultWorkers reads names of the worker pools the chart creates by default from its values.yaml.
+// The path is either the values file or the chart directory. Pools set to null in the chart are not created,
+// so they are left out.
+func ReadDefaultWorkers(path string) (api.DefaultWorkers, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, schema.ChartValuesFile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	chartValues := struct {
+		Workers map[api.ClusterProvider]map[string]interface{} `json:"workers"`
+	}{}
+	if err = yaml.Unmarshal(data, &chartValues); err != nil {
+		return nil, fmt.Errorf("invalid chart values %s: %w", path, err)
+	}
+
+	result := api.DefaultWorkers{}
+	for provider, workers := range chartValues.Workers {
+		names := make([]string, 0, len(workers))
+		for name, worker := range workers {
+			if worker != nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		result[provider] = names
 	}
 
+	return result, nil
 }
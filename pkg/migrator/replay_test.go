@@ -0,0 +1,157 @@
+package migrator
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/diff"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+	"github.com/pluralsh/cluster-api-migration/pkg/schema"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// fixtureProviders have a fixture recorded with --record in testdata, i.e. testdata/aws.fixture.json,
+// and values it converts to in a golden file, i.e. testdata/aws.values.yaml.
+var fixtureProviders = []api.ClusterProvider{api.ClusterProviderAWS, api.ClusterProviderAzure, api.ClusterProviderGCP}
+
+func replayFixture(t *testing.T, provider api.ClusterProvider) *api.Values {
+	t.Helper()
+
+	fixture, err := ReadFixture(filepath.Join("testdata", string(provider)+".fixture.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewReplayMigrator(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, _, err := m.Convert(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return values
+}
+
+func TestReplayMatchesGoldenValues(t *testing.T) {
+	for _, provider := range fixtureProviders {
+		t.Run(string(provider), func(t *testing.T) {
+			actual, err := yaml.Marshal(replayFixture(t, provider))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", string(provider)+".values.yaml")
+			document := map[string]interface{}{}
+			if err = yaml.Unmarshal(actual, &document); err != nil {
+				t.Fatal(err)
+			}
+			if err = schema.Validate(document); err != nil {
+				t.Errorf("converted values do not match the values schema: %s", err)
+			}
+
+			if *update {
+				if err = os.WriteFile(golden, actual, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("converted values differ from %s, run go test ./pkg/migrator -update if the change is expected:\n%s", golden, actual)
+			}
+		})
+	}
+}
+
+// TestReplayRoundTripsThroughManifests checks that verify reports no drift right after the cluster was adopted,
+// i.e. that values read back from the rendered manifests match the converted ones.
+func TestReplayRoundTripsThroughManifests(t *testing.T) {
+	for _, provider := range fixtureProviders {
+		t.Run(string(provider), func(t *testing.T) {
+			values := replayFixture(t, provider)
+
+			objects, err := manifests.Render(values, api.NewConversionReport())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			managed, err := manifests.Values(clusterObjects(t, objects), api.NewConversionReport())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			unmanaged, err := manifests.Unmanaged(values)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changes, err := diff.Drift(managed, values, unmanaged...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, change := range changes {
+				t.Errorf("drift after round trip: %s", change)
+			}
+		})
+	}
+}
+
+// clusterObjects picks the objects manifests.Read would get from the management cluster out of the rendered ones.
+func clusterObjects(t *testing.T, objects []*unstructured.Unstructured) *api.ClusterObjects {
+	t.Helper()
+
+	byKindAndName := map[string]*unstructured.Unstructured{}
+	for _, object := range objects {
+		byKindAndName[object.GetKind()+"/"+object.GetName()] = object
+	}
+
+	result := &api.ClusterObjects{}
+	for _, object := range objects {
+		switch object.GetKind() {
+		case "Cluster":
+			result.Cluster = &clusterv1.Cluster{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, result.Cluster); err != nil {
+				t.Fatal(err)
+			}
+		case "MachinePool":
+			pool := &expv1.MachinePool{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, pool); err != nil {
+				t.Fatal(err)
+			}
+			reference := pool.Spec.Template.Spec.InfrastructureRef
+			result.MachinePools = append(result.MachinePools, api.MachinePoolObjects{
+				MachinePool:    pool,
+				Infrastructure: byKindAndName[reference.Kind+"/"+reference.Name],
+			})
+		}
+	}
+
+	if result.Cluster == nil {
+		t.Fatal("rendered manifests do not contain Cluster")
+	}
+	result.ControlPlane = byKindAndName[result.Cluster.Spec.ControlPlaneRef.Kind+"/"+result.Cluster.Spec.ControlPlaneRef.Name]
+	result.Infrastructure = byKindAndName[result.Cluster.Spec.InfrastructureRef.Kind+"/"+result.Cluster.Spec.InfrastructureRef.Name]
+
+	return result
+}
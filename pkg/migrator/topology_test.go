@@ -0,0 +1,109 @@
+package migrator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/manifests"
+)
+
+// TestRenderTopologySharesClassesOfIdenticalPools copies the ssod pool of the azure fixture under another name,
+// so that the copy differs only in the agent pool name and has to use the class of ssod.
+func TestRenderTopologySharesClassesOfIdenticalPools(t *testing.T) {
+	values := replayFixture(t, api.ClusterProviderAzure)
+	workers := *values.Workers.AzureWorkers
+
+	data, err := json.Marshal(workers["ssod"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := &api.AzureWorker{}
+	if err = json.Unmarshal(data, copied); err != nil {
+		t.Fatal(err)
+	}
+	workers["ssod2"] = copied
+
+	objects, err := manifests.RenderTopology(values, api.NewConversionReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	class := &clusterv1.ClusterClass{}
+	cluster := &clusterv1.Cluster{}
+	poolTemplates := map[string]*unstructured.Unstructured{}
+	for _, object := range objects {
+		switch object.GetKind() {
+		case "ClusterClass":
+			if err = runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, class); err != nil {
+				t.Fatal(err)
+			}
+		case "Cluster":
+			if err = runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, cluster); err != nil {
+				t.Fatal(err)
+			}
+		case "AzureManagedMachinePoolTemplate":
+			poolTemplates[object.GetName()] = object
+		}
+	}
+
+	classes := []string{}
+	for _, poolClass := range class.Spec.Workers.MachinePools {
+		classes = append(classes, poolClass.Class)
+	}
+	if !reflect.DeepEqual(classes, []string{"msspot", "ssod"}) {
+		t.Errorf("machine pool classes = %v, expected msspot and ssod", classes)
+	}
+	if len(poolTemplates) != 2 {
+		t.Errorf("rendered %d machine pool templates, expected one per class", len(poolTemplates))
+	}
+	for name, template := range poolTemplates {
+		if _, ok, _ := unstructured.NestedFieldNoCopy(template.Object, "spec", "template", "spec", "name"); ok {
+			t.Errorf("template %s sets the pool name, which is a pool variable", name)
+		}
+	}
+
+	if cluster.Spec.Topology == nil || cluster.Spec.Topology.Workers == nil {
+		t.Fatal("cluster has no workers topology")
+	}
+
+	type pool struct{ class, name string }
+	expected := map[string]pool{
+		"msspot": {class: "msspot", name: "msspot"},
+		"ssod":   {class: "ssod", name: "ssod"},
+		"ssod2":  {class: "ssod", name: "ssod2"},
+	}
+	actual := map[string]pool{}
+	for _, machinePool := range cluster.Spec.Topology.Workers.MachinePools {
+		if machinePool.Variables == nil || len(machinePool.Variables.Overrides) != 1 {
+			t.Errorf("machine pool %s has variables %+v, expected override of the pool name", machinePool.Name, machinePool.Variables)
+			continue
+		}
+
+		override := machinePool.Variables.Overrides[0]
+		var name string
+		if err = json.Unmarshal(override.Value.Raw, &name); err != nil || override.Name != "machinePoolName" {
+			t.Errorf("machine pool %s overrides %s with %s, expected machinePoolName", machinePool.Name, override.Name, override.Value.Raw)
+			continue
+		}
+		actual[machinePool.Name] = pool{class: machinePool.Class, name: name}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("machine pools = %v, expected %v", actual, expected)
+	}
+}
+
+func TestRenderTopologyFailsForProvidersWithoutClusterClass(t *testing.T) {
+	for _, provider := range []api.ClusterProvider{api.ClusterProviderAWS, api.ClusterProviderGCP} {
+		t.Run(string(provider), func(t *testing.T) {
+			if _, err := manifests.RenderTopology(replayFixture(t, provider), api.NewConversionReport()); err == nil {
+				t.Errorf("%s values were rendered as topology", provider)
+			}
+		})
+	}
+}
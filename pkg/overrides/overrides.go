@@ -0,0 +1,136 @@
+package overrides
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/diff"
+)
+
+// Apply deep-merges override files into the values in the given order and records every field they changed
+// in the report. Objects, i.e. AWSWorkers, are merged by key and null deletes the key, lists are replaced
+// as a whole. Overrides are partial values of the current version, unknown fields are errors.
+func Apply(values *api.Values, report *api.ConversionReport, paths ...string) (*api.Values, error) {
+	for _, path := range paths {
+		overrides, err := Read(path)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := Merge(values, overrides)
+		if err != nil {
+			return nil, fmt.Errorf("cannot apply overrides %s: %w", path, err)
+		}
+
+		changes, err := diff.Values(values, result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range changes {
+			record(report, change, filepath.Base(path))
+		}
+
+		values = result
+	}
+
+	report.Sort()
+	return values, nil
+}
+
+// Read reads override file in YAML or JSON.
+func Read(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]interface{}{}
+	if err = yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid overrides file %s: %w", path, err)
+	}
+
+	if version, ok := overrides["apiVersion"]; ok && version != api.Version {
+		return nil, fmt.Errorf("overrides file %s is not %s, only values of the current version can be overridden", path, api.Version)
+	}
+
+	return overrides, nil
+}
+
+// Merge returns copy of the values with the overrides merged into them. The values are not modified.
+func Merge(values *api.Values, overrides map[string]interface{}) (*api.Values, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	document := map[string]interface{}{}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	if data, err = json.Marshal(merge(document, overrides)); err != nil {
+		return nil, err
+	}
+
+	result := &api.Values{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(result); err != nil {
+		return nil, err
+	}
+
+	result.Sort()
+	return result, nil
+}
+
+// merge applies overrides to the document like JSON merge patch (RFC 7386).
+func merge(document, overrides map[string]interface{}) map[string]interface{} {
+	for key, value := range overrides {
+		if value == nil {
+			delete(document, key)
+			continue
+		}
+
+		object, isObject := value.(map[string]interface{})
+		current, currentIsObject := document[key].(map[string]interface{})
+		if isObject && currentIsObject {
+			document[key] = merge(current, object)
+			continue
+		}
+
+		if isObject {
+			// Nulls of new objects delete nothing, but would be kept as values.
+			value = merge(map[string]interface{}{}, object)
+		}
+		document[key] = value
+	}
+
+	return document
+}
+
+func record(report *api.ConversionReport, change diff.Change, file string) {
+	switch change.Type {
+	case diff.ChangeTypeAdded:
+		report.Overridden(change.Path, "added by %s: %s", file, format(change.To))
+	case diff.ChangeTypeRemoved:
+		report.Overridden(change.Path, "removed by %s", file)
+	default:
+		report.Overridden(change.Path, "changed by %s: %s -> %s", file, format(change.From), format(change.To))
+	}
+}
+
+func format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
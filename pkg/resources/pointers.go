@@ -3,3 +3,13 @@ package resources
 func Ptr[T any](v T) *T {
 	return &v
 }
+
+// Value returns the value the pointer points to, or zero value of the type if it is nil.
+func Value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+
+	return *p
+}
@@ -1,38 +1,179 @@
 package resources
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 
 	"sigs.k8s.io/yaml"
 )
 
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	// FormatSet prints every value as a key=value line accepted by helm --set, i.e. cluster.aws.region=us-east-1.
+	FormatSet = "set"
+)
+
+// Printer writes documents to any writer and returns errors of marshalling and writing them.
 type Printer interface {
-	PrettyPrint()
+	// Print writes the documents to w. YAML documents are separated by ---, documents of other formats follow each other.
+	Print(w io.Writer, documents ...interface{}) error
+	// PrintFile writes the documents to the file at path, replacing it if it exists.
+	PrintFile(path string, documents ...interface{}) error
+}
+
+// NewPrinter returns printer of the format, one of: yaml, json, set. Empty format defaults to yaml.
+func NewPrinter(format string) (Printer, error) {
+	switch format {
+	case "", FormatYAML:
+		return NewYAMLPrinter(), nil
+	case FormatJSON:
+		return NewJSONPrinter(), nil
+	case FormatSet:
+		return NewSetPrinter(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, use %s, %s or %s", format, FormatYAML, FormatJSON, FormatSet)
+	}
+}
+
+// printer writes documents marshalled by the format, joined by the separator.
+type printer struct {
+	marshal   func(i interface{}) ([]byte, error)
+	separator string
+}
+
+func (p *printer) Print(w io.Writer, documents ...interface{}) error {
+	for i, document := range documents {
+		data, err := p.marshal(document)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			if _, err = io.WriteString(w, p.separator); err != nil {
+				return err
+			}
+		}
+
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-type jsonPrinter struct {
-	i interface{}
+func (p *printer) PrintFile(path string, documents ...interface{}) error {
+	buffer := &bytes.Buffer{}
+	if err := p.Print(buffer, documents...); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0644)
 }
 
-func (this *jsonPrinter) PrettyPrint() {
-	s, _ := json.MarshalIndent(this.i, "", "  ")
-	fmt.Println(string(s))
+func NewJSONPrinter() Printer {
+	return &printer{marshal: marshalJSON}
 }
 
-type yamlPrinter struct {
-	i interface{}
+func NewYAMLPrinter() Printer {
+	return &printer{marshal: yaml.Marshal, separator: "---\n"}
 }
 
-func (this *yamlPrinter) PrettyPrint() {
-	s, _ := yaml.Marshal(this.i)
-	fmt.Println(string(s))
+// NewSetPrinter returns printer of flattened values for helm --set. Keys are sorted and list items are indexed,
+// i.e. cluster.podCidrBlocks[0]=10.0.0.0/16. Helm infers types of the values, so strings that look like numbers
+// or booleans have to be passed with --set-string instead.
+func NewSetPrinter() Printer {
+	return &printer{marshal: marshalSet}
 }
 
-func NewJsonPrinter(i interface{}) Printer {
-	return &jsonPrinter{i: i}
+func marshalJSON(i interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
 }
 
-func NewYAMLPrinter(i interface{}) Printer {
-	return &yamlPrinter{i: i}
+func marshalSet(i interface{}) ([]byte, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err = decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	flatten("", document, &lines)
+
+	buffer := &bytes.Buffer{}
+	for _, line := range lines {
+		buffer.WriteString(line)
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// flatten appends key=value lines of the leaves of the document. Empty objects do not change values
+// and are left out, empty lists are printed as {}.
+func flatten(key string, value interface{}, lines *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			child := escapeKey(k)
+			if len(key) > 0 {
+				child = key + "." + child
+			}
+			flatten(child, v[k], lines)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			*lines = append(*lines, key+"={}")
+			return
+		}
+
+		for i, item := range v {
+			flatten(fmt.Sprintf("%s[%d]", key, i), item, lines)
+		}
+	case nil:
+		*lines = append(*lines, key+"=null")
+	case string:
+		*lines = append(*lines, key+"="+escapeValue(v))
+	default:
+		*lines = append(*lines, fmt.Sprintf("%s=%v", key, v))
+	}
+}
+
+// escapeKey escapes characters helm --set splits keys on, i.e. dots of label names.
+func escapeKey(key string) string {
+	return strings.NewReplacer(`\`, `\\`, ".", `\.`, ",", `\,`, "=", `\=`, "[", `\[`).Replace(key)
+}
+
+// escapeValue escapes commas that separate values and a leading brace that starts a list.
+func escapeValue(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, ",", `\,`).Replace(value)
+	if strings.HasPrefix(value, "{") {
+		value = `\` + value
+	}
+
+	return value
 }
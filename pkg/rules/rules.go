@@ -0,0 +1,332 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+)
+
+// namePlaceholder in Rule.Rename is replaced with the current name of the pool.
+const namePlaceholder = "{name}"
+
+// Rules rewrite worker pools of converted values, i.e. to follow a naming convention or to move pools
+// to new instance types. They are stored in YAML or JSON file, i.e.:
+//
+//	rules:
+//	- name: naming
+//	  selector:
+//	    name: ng-*
+//	  rename: workers-{name}
+//	- selector:
+//	    labels:
+//	      workload: batch
+//	  spot: true
+//	  instanceTypes:
+//	    m5.large: m6i.large
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule applies its actions to every pool matched by the selector. Rules are applied in order,
+// so later rules see pools renamed by the earlier ones.
+type Rule struct {
+	// Name identifies the rule in the report. Defaults to its position, i.e. rule 1.
+	Name     string   `json:"name,omitempty"`
+	Selector Selector `json:"selector"`
+	// Drop removes matched pools from the values, i.e. system pools that are managed elsewhere.
+	// It cannot be combined with other actions.
+	Drop bool `json:"drop,omitempty"`
+	// Rename sets new name of matched pools, {name} is replaced with the current one, i.e. workers-{name}.
+	Rename string `json:"rename,omitempty"`
+	// InstanceTypes maps old instance types, VM SKUs or machine types to new ones. Other types are kept.
+	InstanceTypes map[string]string `json:"instanceTypes,omitempty"`
+	// Spot forces spot instances if true or regular ones if false.
+	Spot *bool `json:"spot,omitempty"`
+}
+
+// Selector matches pools of every provider by their name and node labels. Empty selector matches every pool.
+type Selector struct {
+	// Name is a glob matched against the pool name, i.e. ng-*.
+	Name string `json:"name,omitempty"`
+	// Labels have to be set on the nodes of the pool. Empty value matches any value of the label.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Read reads rules file in YAML or JSON and validates it.
+func Read(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &Rules{}
+	if err = yaml.UnmarshalStrict(data, rules); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	if err = rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// Validate checks that globs are well-formed and that every rule has an action.
+func (r *Rules) Validate() error {
+	for i, rule := range r.Rules {
+		name := rule.name(i)
+
+		if _, err := path.Match(rule.Selector.Name, ""); err != nil {
+			return fmt.Errorf("%s: invalid name glob %q: %w", name, rule.Selector.Name, err)
+		}
+
+		hasAction := len(rule.Rename) > 0 || len(rule.InstanceTypes) > 0 || rule.Spot != nil
+		switch {
+		case rule.Drop && hasAction:
+			return fmt.Errorf("%s: drop cannot be combined with other actions", name)
+		case !rule.Drop && !hasAction:
+			return fmt.Errorf("%s: no action, set drop, rename, instanceTypes or spot", name)
+		}
+	}
+
+	return nil
+}
+
+// Apply rewrites worker pools of the values in place and records every change in the report.
+func (r *Rules) Apply(values *api.Values, report *api.ConversionReport) error {
+	if r == nil {
+		return nil
+	}
+
+	for i, rule := range r.Rules {
+		var err error
+		switch {
+		case values.Workers.AWSWorkers != nil:
+			err = apply(rule, rule.name(i), "aws", *values.Workers.AWSWorkers, awsPool, report)
+		case values.Workers.AzureWorkers != nil:
+			err = apply(rule, rule.name(i), "azure", *values.Workers.AzureWorkers, azurePool, report)
+		case values.Workers.GCPWorkers != nil:
+			err = apply(rule, rule.name(i), "gcp", *values.Workers.GCPWorkers, gcpPool, report)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	report.Sort()
+	return nil
+}
+
+func (r Rule) name(i int) string {
+	if len(r.Name) > 0 {
+		return r.Name
+	}
+
+	return "rule " + strconv.Itoa(i+1)
+}
+
+// pool is the view of a worker of any provider the rules work on.
+type pool interface {
+	// labels returns labels of the nodes of the pool.
+	labels() map[string]string
+	// instanceType returns the instance type, VM SKU or machine type of the pool and path of its field.
+	instanceType() (string, string)
+	setInstanceType(instanceType string)
+	// spot returns whether the pool runs spot instances and path of the field that controls it.
+	spot() (bool, string)
+	setSpot(spot bool)
+}
+
+// apply runs the rule on the workers of one provider. Workers are renamed after all of them were matched,
+// so that a renamed pool is not matched again by its new name.
+func apply[W any](rule Rule, name, provider string, workers map[string]*W, newPool func(*W) pool, report *api.ConversionReport) error {
+	matched := make([]string, 0)
+	for workerName, worker := range workers {
+		// Null workers are default pools of the chart that are not created.
+		if worker != nil && rule.Selector.matches(workerName, newPool(worker)) {
+			matched = append(matched, workerName)
+		}
+	}
+	sort.Strings(matched)
+
+	renamed := map[string]*W{}
+	for _, workerName := range matched {
+		worker := workers[workerName]
+		p := newPool(worker)
+		workerPath := api.Path("workers", provider, workerName)
+
+		if rule.Drop {
+			delete(workers, workerName)
+			report.Transformed(workerPath, "dropped by %s", name)
+			continue
+		}
+
+		if current, field := p.instanceType(); len(rule.InstanceTypes[current]) > 0 && rule.InstanceTypes[current] != current {
+			p.setInstanceType(rule.InstanceTypes[current])
+			report.Transformed(api.Path(workerPath, field), "changed by %s: %s -> %s", name, current, rule.InstanceTypes[current])
+		}
+
+		if current, field := p.spot(); rule.Spot != nil && *rule.Spot != current {
+			p.setSpot(*rule.Spot)
+			report.Transformed(api.Path(workerPath, field), "spot set to %t by %s", *rule.Spot, name)
+		}
+
+		if len(rule.Rename) > 0 {
+			newName := strings.ReplaceAll(rule.Rename, namePlaceholder, workerName)
+			if newName != workerName {
+				if _, ok := renamed[newName]; ok {
+					return fmt.Errorf("%s renames more than one of %s workers to %s", name, provider, newName)
+				}
+				delete(workers, workerName)
+				renamed[newName] = worker
+				report.Transformed(workerPath, "renamed to %s by %s", newName, name)
+			}
+		}
+	}
+
+	for newName, worker := range renamed {
+		if existing, ok := workers[newName]; ok && existing != nil {
+			return fmt.Errorf("%s renames %s workers to %s, which already exists", name, provider, newName)
+		}
+		workers[newName] = worker
+	}
+
+	return nil
+}
+
+func (s Selector) matches(name string, p pool) bool {
+	if len(s.Name) > 0 {
+		if ok, _ := path.Match(s.Name, name); !ok {
+			return false
+		}
+	}
+
+	labels := p.labels()
+	for key, value := range s.Labels {
+		actual, ok := labels[key]
+		if !ok || (len(value) > 0 && value != actual) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type awsWorker struct{ *api.AWSWorker }
+
+func awsPool(worker *api.AWSWorker) pool {
+	return awsWorker{worker}
+}
+
+func (w awsWorker) labels() map[string]string {
+	return mergeLabels(derefLabels(w.Labels), derefLabels(w.Spec.Labels))
+}
+
+func (w awsWorker) instanceType() (string, string) {
+	return resources.Value(w.Spec.InstanceType), "spec.instanceType"
+}
+
+func (w awsWorker) setInstanceType(instanceType string) {
+	w.Spec.InstanceType = &instanceType
+}
+
+func (w awsWorker) spot() (bool, string) {
+	return w.Spec.CapacityType == api.ManagedMachinePoolCapacityTypeSpot, "spec.capacityType"
+}
+
+func (w awsWorker) setSpot(spot bool) {
+	w.Spec.CapacityType = api.ManagedMachinePoolCapacityTypeOnDemand
+	if spot {
+		w.Spec.CapacityType = api.ManagedMachinePoolCapacityTypeSpot
+	}
+}
+
+type azureWorker struct{ *api.AzureWorker }
+
+func azurePool(worker *api.AzureWorker) pool {
+	return azureWorker{worker}
+}
+
+func (w azureWorker) labels() map[string]string {
+	return mergeLabels(w.Labels, derefLabels(w.Spec.NodeLabels))
+}
+
+func (w azureWorker) instanceType() (string, string) {
+	return w.Spec.SKU, "spec.sku"
+}
+
+func (w azureWorker) setInstanceType(instanceType string) {
+	w.Spec.SKU = instanceType
+}
+
+func (w azureWorker) spot() (bool, string) {
+	return strings.EqualFold(resources.Value(w.Spec.ScaleSetPriority), "Spot"), "spec.scaleSetPriority"
+}
+
+func (w azureWorker) setSpot(spot bool) {
+	priority := "Regular"
+	if spot {
+		priority = "Spot"
+	}
+	w.Spec.ScaleSetPriority = &priority
+}
+
+type gcpWorker struct{ *api.GCPWorker }
+
+func gcpPool(worker *api.GCPWorker) pool {
+	return gcpWorker{worker}
+}
+
+func (w gcpWorker) labels() map[string]string {
+	var kubernetesLabels map[string]string
+	if w.Spec.KubernetesLabels != nil {
+		kubernetesLabels = *w.Spec.KubernetesLabels
+	}
+
+	return mergeLabels(w.Labels, kubernetesLabels)
+}
+
+func (w gcpWorker) instanceType() (string, string) {
+	return w.Spec.MachineType, "spec.machineType"
+}
+
+func (w gcpWorker) setInstanceType(instanceType string) {
+	w.Spec.MachineType = instanceType
+}
+
+func (w gcpWorker) spot() (bool, string) {
+	return w.Spec.Spot, "spec.spot"
+}
+
+func (w gcpWorker) setSpot(spot bool) {
+	w.Spec.Spot = spot
+}
+
+func derefLabels(labels map[string]*string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for key, value := range labels {
+		result[key] = resources.Value(value)
+	}
+
+	return result
+}
+
+// mergeLabels returns union of the label maps, later maps take precedence.
+func mergeLabels(labels ...map[string]string) map[string]string {
+	result := map[string]string{}
+	for _, l := range labels {
+		for key, value := range l {
+			result[key] = value
+		}
+	}
+
+	return result
+}
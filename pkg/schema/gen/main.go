@@ -0,0 +1,85 @@
+// Command gen writes JSON Schema of api.Values to the file given as the argument. Comments of the types
+// are read from sources of their packages, so it has to be run from the module, i.e. with go generate.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/api"
+	"github.com/pluralsh/cluster-api-migration/pkg/schema"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: gen <output file>")
+		os.Exit(2)
+	}
+
+	if err := generate(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(path string) error {
+	root := reflect.TypeOf(api.Values{})
+
+	packages := map[string]bool{}
+	collectPackages(root, packages, map[reflect.Type]bool{})
+
+	paths := make([]string, 0, len(packages))
+	for p := range packages {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	generator := &schema.Generator{Comments: map[string]schema.PackageComments{}}
+	for _, p := range paths {
+		pkg, err := build.Import(p, ".", build.FindOnly)
+		if err != nil {
+			return err
+		}
+
+		comments, err := schema.ParseComments(pkg.Dir)
+		if err != nil {
+			return fmt.Errorf("cannot read comments of %s: %w", p, err)
+		}
+		generator.Comments[p] = comments
+	}
+
+	data, err := json.MarshalIndent(generator.Generate(root), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// collectPackages adds import paths of the named types reachable from t.
+func collectPackages(t reflect.Type, packages map[string]bool, seen map[reflect.Type]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+
+	if len(t.PkgPath()) > 0 {
+		packages[t.PkgPath()] = true
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		collectPackages(t.Elem(), packages, seen)
+	case reflect.Map:
+		collectPackages(t.Key(), packages, seen)
+		collectPackages(t.Elem(), packages, seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			collectPackages(t.Field(i).Type, packages, seen)
+		}
+	}
+}
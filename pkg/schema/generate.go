@@ -0,0 +1,309 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/pluralsh/cluster-api-migration/pkg/resources"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// special are schemas of types with custom JSON encoding.
+var special = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(metav1.Time{}): func() *Schema {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	},
+	reflect.TypeOf(metav1.Duration{}): func() *Schema {
+		return &Schema{Type: Types{"string"}}
+	},
+	reflect.TypeOf(resource.Quantity{}): func() *Schema {
+		return &Schema{AnyOf: []*Schema{{Type: Types{"integer"}}, {Type: Types{"string"}}}}
+	},
+	reflect.TypeOf(intstr.IntOrString{}): func() *Schema {
+		return &Schema{AnyOf: []*Schema{{Type: Types{"integer"}}, {Type: Types{"string"}}}}
+	},
+}
+
+// Generator builds JSON Schema of Go types from their JSON encoding. Descriptions and validations,
+// i.e. enums and limits, are taken from doc comments and kubebuilder markers of the types.
+type Generator struct {
+	// Comments maps import paths of packages to comments of their types. Types of packages missing here
+	// get schema without descriptions and validations.
+	Comments map[string]PackageComments
+
+	definitions map[string]*Schema
+	// pkgPath of the generated type. Its structs are defined by their names, others are prefixed
+	// with the last two elements of their import path, i.e. core.v1.EnvVar.
+	pkgPath string
+}
+
+// Generate returns schema of the struct type. Other structs it references are added to its definitions.
+func (g *Generator) Generate(t reflect.Type) *Schema {
+	g.definitions = map[string]*Schema{}
+	g.pkgPath = t.PkgPath()
+
+	result := g.structSchema(t)
+	result.Schema = draft07
+	result.Description = g.comments(t, "").Description
+	result.Definitions = g.definitions
+	return result
+}
+
+func (g *Generator) typeSchema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return nullable(g.typeSchema(t.Elem()))
+	}
+
+	if schema, ok := special[t]; ok {
+		return schema()
+	}
+
+	if t.Kind() == reflect.Struct {
+		marshaler := t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)
+		if marshaler && !g.comments(t, "").PreserveUnknownFields() {
+			// Encoding of the type is not known, any value is accepted.
+			return &Schema{}
+		}
+
+		return &Schema{Ref: "#/definitions/" + g.definition(t)}
+	}
+
+	result := &Schema{}
+	switch t.Kind() {
+	case reflect.Bool:
+		result.Type = Types{"boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result.Type = Types{"integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result.Type = Types{"integer"}
+		result.Minimum = resources.Ptr(0.0)
+	case reflect.Float32, reflect.Float64:
+		result.Type = Types{"number"}
+	case reflect.String:
+		result.Type = Types{"string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			result.Type = Types{"string"}
+			result.Format = "byte"
+			break
+		}
+		result.Type = Types{"array"}
+		result.Items = g.typeSchema(t.Elem())
+	case reflect.Map:
+		result.Type = Types{"object"}
+		result.AdditionalProperties = g.typeSchema(t.Elem())
+	}
+
+	comments := g.comments(t, "")
+	result.Description = comments.Description
+	apply(result, comments, t.Kind())
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		return nullable(result)
+	}
+
+	return result
+}
+
+// definition adds schema of the struct to the definitions and returns its name.
+func (g *Generator) definition(t reflect.Type) string {
+	name := t.Name()
+	if t.PkgPath() != g.pkgPath {
+		elements := strings.Split(t.PkgPath(), "/")
+		if len(elements) > 2 {
+			elements = elements[len(elements)-2:]
+		}
+		name = strings.Join(append(elements, name), ".")
+	}
+
+	if _, ok := g.definitions[name]; ok {
+		return name
+	}
+
+	// Placeholder stops recursion of self-referencing types.
+	g.definitions[name] = &Schema{}
+	result := g.structSchema(t)
+	result.Description = g.comments(t, "").Description
+	g.definitions[name] = result
+
+	return name
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	result := &Schema{
+		Type:                 Types{"object"},
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if (field.Anonymous && len(name) == 0) || hasOption(options, "inline") {
+			inlined := field.Type
+			if inlined.Kind() == reflect.Pointer {
+				inlined = inlined.Elem()
+			}
+
+			embedded := g.structSchema(inlined)
+			for property, schema := range embedded.Properties {
+				result.Properties[property] = schema
+			}
+			result.Required = append(result.Required, embedded.Required...)
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		comments := g.comments(t, field.Name)
+		schema := g.fieldSchema(field.Type, comments)
+		if len(comments.Description) > 0 {
+			schema.Description = comments.Description
+		}
+		result.Properties[name] = schema
+
+		// Zero values of omitempty fields are left out of the encoding, so such fields cannot be required even with
+		// the Required marker, i.e. the empty OIDC configuration of EKS clusters.
+		if !hasOption(options, "omitempty") && !comments.Optional() {
+			result.Required = append(result.Required, name)
+		}
+	}
+
+	// Types that preserve unknown fields encode them next to their own ones.
+	if g.comments(t, "").PreserveUnknownFields() {
+		result.AdditionalProperties = nil
+	}
+
+	return result
+}
+
+// fieldSchema returns schema of the field type with markers of the field applied.
+func (g *Generator) fieldSchema(t reflect.Type, comments Comments) *Schema {
+	if t.Kind() != reflect.Pointer {
+		schema := g.typeSchema(t)
+		if len(schema.Ref) == 0 {
+			apply(schema, comments, t.Kind())
+		}
+		return schema
+	}
+
+	schema := g.typeSchema(t.Elem())
+	if len(schema.Ref) == 0 {
+		apply(schema, comments, t.Elem().Kind())
+	}
+	return nullable(schema)
+}
+
+func (g *Generator) comments(t reflect.Type, field string) Comments {
+	name := t.Name()
+	if len(field) > 0 {
+		name += "." + field
+	}
+
+	return g.Comments[t.PkgPath()][name]
+}
+
+// apply sets validations of the kubebuilder markers on the schema.
+func apply(schema *Schema, comments Comments, kind reflect.Kind) {
+	if value, ok := comments.Marker("Enum"); ok {
+		schema.Enum = nil
+		for _, item := range strings.Split(value, ";") {
+			schema.Enum = append(schema.Enum, enumValue(strings.Trim(item, `"`), kind))
+		}
+	}
+
+	for marker, target := range map[string]**float64{"Minimum": &schema.Minimum, "Maximum": &schema.Maximum} {
+		if value, ok := comments.Marker(marker); ok {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				*target = &number
+			}
+		}
+	}
+
+	limits := map[string]**int64{
+		"MinLength": &schema.MinLength,
+		"MaxLength": &schema.MaxLength,
+		"MinItems":  &schema.MinItems,
+		"MaxItems":  &schema.MaxItems,
+	}
+	for marker, target := range limits {
+		if value, ok := comments.Marker(marker); ok {
+			if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+				*target = &number
+			}
+		}
+	}
+
+	if value, ok := comments.Marker("Pattern"); ok {
+		schema.Pattern = strings.Trim(value, "`")
+	}
+	if value, ok := comments.Marker("Format"); ok {
+		schema.Format = value
+	}
+}
+
+func enumValue(value string, kind reflect.Kind) interface{} {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+	}
+
+	return value
+}
+
+// nullable allows null in place of the value, as nil pointers, maps and slices are encoded.
+func nullable(schema *Schema) *Schema {
+	switch {
+	case len(schema.Ref) > 0:
+		return &Schema{AnyOf: []*Schema{schema, {Type: Types{"null"}}}}
+	case len(schema.Type) == 0 || contains(schema.Type, "null"):
+		return schema
+	}
+
+	schema.Type = append(schema.Type, "null")
+	if len(schema.Enum) > 0 {
+		schema.Enum = append(schema.Enum, nil)
+	}
+	return schema
+}
+
+func hasOption(options, option string) bool {
+	for _, item := range strings.Split(options, ",") {
+		if item == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(types Types, t string) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const validationMarker = "+kubebuilder:validation:"
+
+// Comments holds documentation and markers of a type or a struct field.
+type Comments struct {
+	Description string
+	// Markers are comment lines starting with +, i.e. +kubebuilder:validation:Minimum=1, without the +.
+	Markers []string
+}
+
+// Marker returns value of the kubebuilder validation marker, i.e. 1 for Minimum of +kubebuilder:validation:Minimum=1.
+// Both = and := separate the value.
+func (c Comments) Marker(name string) (string, bool) {
+	for _, marker := range c.Markers {
+		rest, ok := strings.CutPrefix(marker, validationMarker[1:]+name)
+		if !ok {
+			continue
+		}
+
+		if len(rest) == 0 {
+			return "", true
+		}
+
+		if value, ok := strings.CutPrefix(rest, ":="); ok {
+			return value, true
+		}
+		if value, ok := strings.CutPrefix(rest, "="); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Optional returns true if the field is marked with +optional or +kubebuilder:validation:Optional.
+func (c Comments) Optional() bool {
+	for _, marker := range c.Markers {
+		if marker == "optional" {
+			return true
+		}
+	}
+
+	_, ok := c.Marker("Optional")
+	return ok
+}
+
+// PreserveUnknownFields returns true if the type is marked with +kubebuilder:pruning:PreserveUnknownFields,
+// i.e. if its encoding adds sections of other providers to its fields.
+func (c Comments) PreserveUnknownFields() bool {
+	for _, marker := range c.Markers {
+		if marker == "kubebuilder:pruning:PreserveUnknownFields" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PackageComments maps type names, i.e. Taint, and field names, i.e. Taint.Effect, to their comments.
+type PackageComments map[string]Comments
+
+// ParseComments reads comments of the types declared in the package directory, skipping test files.
+func ParseComments(dir string) (PackageComments, error) {
+	fileSet := token.NewFileSet()
+	packages, err := parser.ParseDir(fileSet, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	result := PackageComments{}
+	for name, p := range packages {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+
+		for path, file := range p.Files {
+			if filepath.Dir(path) != filepath.Clean(dir) {
+				continue
+			}
+
+			for _, declaration := range file.Decls {
+				genDecl, ok := declaration.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					doc := typeSpec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					result[typeSpec.Name.Name] = parseComments(doc)
+
+					structType, ok := typeSpec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+
+					for _, field := range structType.Fields.List {
+						for _, fieldName := range field.Names {
+							result[typeSpec.Name.Name+"."+fieldName.Name] = parseComments(field.Doc)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func parseComments(group *ast.CommentGroup) Comments {
+	result := Comments{}
+	if group == nil {
+		return result
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(group.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "+"):
+			result.Markers = append(result.Markers, strings.TrimPrefix(line, "+"))
+		case len(line) > 0:
+			lines = append(lines, line)
+		}
+	}
+	result.Description = strings.Join(lines, " ")
+
+	return result
+}
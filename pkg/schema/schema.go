@@ -0,0 +1,66 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:generate go run ./gen values.schema.json
+
+// values is the JSON Schema of api.Values generated from the Go types and their kubebuilder markers.
+//
+//go:embed values.schema.json
+var values []byte
+
+// Values returns the JSON Schema of api.Values.
+func Values() []byte {
+	return values
+}
+
+// Schema is the subset of JSON Schema draft-07 used by the generator.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Types is a single JSON type or a list of them, i.e. ["object", "null"] for pointers.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// ValidationError lists fields of the values that do not match the schema.
+type ValidationError struct {
+	// Schema names the schema the values were validated against, i.e. path of the chart schema.
+	Schema string
+	// Fields are descriptions of the mismatches, i.e. cluster.aws.region: String length must be greater than or equal to 1.
+	Fields []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("values do not match %s: %s", e.Schema, strings.Join(e.Fields, ", "))
+}
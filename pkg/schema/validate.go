@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ChartSchemaFile is the name of the schema file in the chart directory.
+	ChartSchemaFile = "values.schema.json"
+	// ChartValuesFile is the name of the file with default values in the chart directory.
+	ChartValuesFile = "values.yaml"
+)
+
+// Validate checks values against the schema generated from the Go types, i.e. enums and limits of kubebuilder markers.
+func Validate(values interface{}) error {
+	document, err := toDocument(values)
+	if err != nil {
+		return err
+	}
+
+	return validate("values schema", gojsonschema.NewBytesLoader(Values()), document)
+}
+
+// ValidateChart checks values against values.schema.json of the cluster-api-cluster chart. The path is either
+// the schema file or the chart directory. Defaults from values.yaml next to the schema are merged first and
+// null values remove keys, as Helm does before validating.
+func ValidateChart(values interface{}, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, ChartSchemaFile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	document, err := toDocument(values)
+	if err != nil {
+		return err
+	}
+
+	defaults, err := readDefaults(filepath.Join(filepath.Dir(path), ChartValuesFile))
+	if err != nil {
+		return err
+	}
+
+	return validate(path, gojsonschema.NewBytesLoader(data), coalesce(document, defaults))
+}
+
+func validate(name string, schema gojsonschema.JSONLoader, document map[string]interface{}) error {
+	result, err := gojsonschema.Validate(schema, gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return fmt.Errorf("cannot validate values against %s: %w", name, err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	validationError := &ValidationError{Schema: name}
+	for _, resultError := range result.Errors() {
+		validationError.Fields = append(validationError.Fields, fmt.Sprintf("%s: %s", resultError.Field(), resultError.Description()))
+	}
+
+	return validationError
+}
+
+// readDefaults returns default values of the chart, or nothing if the chart has no values.yaml.
+func readDefaults(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := map[string]interface{}{}
+	if err = yaml.Unmarshal(data, &defaults); err != nil {
+		return nil, fmt.Errorf("invalid chart values %s: %w", path, err)
+	}
+
+	return defaults, nil
+}
+
+// toDocument converts values to their JSON form, so that they are validated the same way as values files.
+func toDocument(values interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	document := map[string]interface{}{}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// coalesce merges values over the defaults. Objects are merged recursively and null values remove keys.
+func coalesce(values, defaults map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(defaults)+len(values))
+	for key, value := range defaults {
+		result[key] = value
+	}
+
+	for key, value := range values {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+
+		valueMap, isMap := value.(map[string]interface{})
+		if !isMap {
+			result[key] = value
+			continue
+		}
+
+		defaultMap, _ := result[key].(map[string]interface{})
+		result[key] = coalesce(valueMap, defaultMap)
+	}
+
+	return result
+}